@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,7 +10,10 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Cron schedules may name any time zone, even on hosts without zoneinfo.
+	_ "time/tzdata"
 	"urlChecker/internal/application/service"
+	"urlChecker/internal/infrastructure/config"
 	httpInfra "urlChecker/internal/infrastructure/http"
 	"urlChecker/internal/infrastructure/logger"
 	"urlChecker/internal/infrastructure/repository"
@@ -17,6 +21,10 @@ import (
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	dbPath := "./data/monitors.db"
 
@@ -35,8 +43,27 @@ func main() {
 	}
 	defer fileLogger.Close()
 
-	monitorService := service.NewMonitorService(repo)
-	checkerService := service.NewCheckerService(repo, fileLogger)
+	monitorService := service.NewMonitorService(repo, repo)
+	if err := monitorService.SetIntervalBounds(cfg.MinInterval, cfg.MaxInterval); err != nil {
+		log.Fatalf("Invalid interval bounds: %v", err)
+	}
+	checkerService := service.NewCheckerService(repo, repo, fileLogger)
+	if err := checkerService.SetDefaultProxy(cfg.DefaultProxy); err != nil {
+		log.Fatalf("Invalid URLCHECKER_PROXY: %v", err)
+	}
+	if err := checkerService.SetConcurrency(cfg.Workers, cfg.PerHostLimit, cfg.QueueSize); err != nil {
+		log.Fatalf("Invalid concurrency limits: %v", err)
+	}
+	if err := checkerService.SetJitter(cfg.Jitter); err != nil {
+		log.Fatalf("Invalid URLCHECKER_JITTER: %v", err)
+	}
+	if cfg.LeaseTTL > 0 {
+		if err := checkerService.SetLeases(cfg.Instance, cfg.LeaseTTL); err != nil {
+			log.Fatalf("Invalid URLCHECKER_LEASE_TTL: %v", err)
+		}
+	}
+	monitorService.SetListener(checkerService)
+	expvar.Publish("checker", expvar.Func(func() any { return checkerService.Stats() }))
 	handler := api.NewHandler(monitorService)
 	router := httpInfra.NewRouter(handler)
 
@@ -49,7 +76,11 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go checkerService.Start(ctx)
+	checkerDone := make(chan struct{})
+	go func() {
+		checkerService.Start(ctx)
+		close(checkerDone)
+	}()
 
 	go func() {
 		fmt.Println("Server started on http://localhost:8080")
@@ -72,6 +103,8 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	// Let the checker release its leases so other instances take over now.
+	<-checkerDone
 
 	fmt.Println("Server stopped")
 }
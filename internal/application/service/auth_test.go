@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+// newTokenServer имитирует OAuth2 token endpoint и считает выданные токены
+func newTokenServer(t *testing.T, issued *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "checker" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "read" {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+		atomic.AddInt32(issued, 1)
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token-1", "token_type": "Bearer", "expires_in": 3600})
+	}))
+}
+
+func TestHTTPProber_OAuth2CachesToken(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, &issued)
+	defer tokenServer.Close()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer target.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+
+	m := monitor.NewURLMonitor(target.URL, time.Minute)
+	m.SetAuth(&monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: tokenServer.URL, ClientID: "checker", ClientSecret: "s3cret", Scopes: []string{"read"}})
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	for _, result := range results {
+		if result.Status != monitor.StatusUp {
+			t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+		}
+	}
+	// Второй чек должен использовать закешированный токен
+	if n := atomic.LoadInt32(&issued); n != 1 {
+		t.Errorf("expected 1 token request, got %d", n)
+	}
+}
+
+func TestHTTPProber_OAuth2TokenFailure(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, &issued)
+	defer tokenServer.Close()
+
+	m := monitor.NewURLMonitor("http://127.0.0.1:1", time.Minute)
+	m.SetAuth(&monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: tokenServer.URL, ClientID: "checker", ClientSecret: "wrong"})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown || result.Error == "" {
+		t.Errorf("expected down with token error, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestHTTPProber_StaticAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if ok && user == "admin" && pass == "secret" {
+			return
+		}
+		if r.Header.Get("Authorization") == "Bearer abc" {
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	for _, auth := range []*monitor.AuthConfig{
+		{Type: monitor.AuthBasic, Username: "admin", Password: "secret"},
+		{Type: monitor.AuthBearer, Token: "abc"},
+	} {
+		m := monitor.NewURLMonitor(server.URL, time.Minute)
+		m.SetAuth(auth)
+
+		result := checkOnce(t, m)
+
+		if result.Status != monitor.StatusUp {
+			t.Errorf("expected %s auth to pass, got %s (%s)", auth.Type, result.Status, result.Error)
+		}
+	}
+}
+
+func TestHTTPProber_OAuth2TokenPerSecret(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, &issued)
+	defer tokenServer.Close()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+
+	valid := monitor.NewURLMonitor(target.URL, time.Minute)
+	valid.SetAuth(&monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: tokenServer.URL, ClientID: "checker", ClientSecret: "s3cret", Scopes: []string{"read"}})
+	revoked := monitor.NewURLMonitor(target.URL, time.Minute)
+	revoked.SetAuth(&monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: tokenServer.URL, ClientID: "checker", ClientSecret: "revoked", Scopes: []string{"read"}})
+	repo.Save(valid)
+	repo.Save(revoked)
+
+	checker.checkURL(context.Background(), valid)
+	checker.checkURL(context.Background(), revoked)
+
+	// Токен, выданный по верному секрету, не достается монитору с неверным
+	results, _ := repo.FindResults(revoked.ID, time.Time{}, time.Time{})
+	if len(results) != 1 || results[0].Status != monitor.StatusDown {
+		t.Errorf("expected the monitor with a revoked secret to be down, got %+v", results)
+	}
+}
+
+func TestTokenCache_SlowEndpointBlocksOnlyItsChecks(t *testing.T) {
+	release := make(chan struct{})
+	var issued int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&issued, 1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "slow", "expires_in": 3600})
+	}))
+	defer slow.Close()
+	defer close(release)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "fast", "expires_in": 3600})
+	}))
+	defer fast.Close()
+
+	cache := newTokenCache()
+	slowAuth := &monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: slow.URL, ClientID: "a", ClientSecret: "x"}
+	for range 3 {
+		go cache.token(context.Background(), http.DefaultClient, slowAuth)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	fastAuth := &monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: fast.URL, ClientID: "b", ClientSecret: "y"}
+	if token, err := cache.token(ctx, http.DefaultClient, fastAuth); err != nil || token != "fast" {
+		t.Errorf("expected the fast endpoint not to wait for the slow one, got %q, %v", token, err)
+	}
+
+	// Ожидающие проверки разделяют один запрос токена
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&issued); n != 1 {
+		t.Errorf("expected one request to the slow endpoint, got %d", n)
+	}
+}
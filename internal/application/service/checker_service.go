@@ -2,80 +2,496 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand/v2"
 	"net/http"
+	"sync"
 	"time"
 	"urlChecker/internal/domain/monitor"
 )
 
+const minScheduleInterval = time.Second
+
+// loadRetryDelay is how soon a due monitor that failed to load is retried,
+// and the longest wait between attempts at the initial load of monitors,
+// which starts from a second and doubles.
+const loadRetryDelay = 10 * time.Second
+
+// heartbeatMargin delays the check of a heartbeat monitor past its
+// deadline, so that it finds the ping overdue.
+const heartbeatMargin = time.Second
+
+// maxCheckDuration bounds a check including its retries, so one monitor
+// cannot hold a worker for long.
+const maxCheckDuration = 10 * time.Minute
+
 type Logger interface {
 	LogCheck(monitorID, url string, statusCode int, responseTime time.Duration, err error)
 }
 
 type CheckerService struct {
-	repo   monitor.Repository
-	client *http.Client
-	logger Logger
+	repo       monitor.Repository
+	results    monitor.ResultRepository
+	client     *http.Client
+	logger     Logger
+	probers    map[monitor.Type]Prober
+	tokens     *tokenCache
+	transports *transportPool
+	pool       *checkPool
+	schedule   *schedule
+	// jitter is the upper bound of a random delay added to each run.
+	jitter time.Duration
+	// defaultProxy applies to http monitors without their own proxy.
+	defaultProxy string
+	// owner and leaseTTL are set when several instances share the
+	// repository; each monitor is then checked by the instance leasing it.
+	owner    string
+	leaseTTL time.Duration
+	// loadedAt is when monitors were last loaded from the repository.
+	loadedAt time.Time
+	// windows caches the maintenance windows; nil until loaded.
+	windowsMu sync.Mutex
+	windows   []*monitor.MaintenanceWindow
+}
+
+func NewCheckerService(repo monitor.Repository, results monitor.ResultRepository, logger Logger) *CheckerService {
+	s := &CheckerService{
+		repo:    repo,
+		results: results,
+		logger:  logger,
+		// Timeouts come from each monitor through the request context.
+		client:     &http.Client{},
+		tokens:     newTokenCache(),
+		transports: newTransportPool(),
+	}
+	s.pool = newCheckPool(s.checkURL)
+	s.schedule = newSchedule()
+	s.probers = map[monitor.Type]Prober{
+		monitor.TypeHTTP:        ProberFunc(s.probeHTTP),
+		monitor.TypeTCP:         newTCPProber(),
+		monitor.TypeUDP:         newUDPProber(),
+		monitor.TypeDNS:         newDNSProber(),
+		monitor.TypeWebSocket:   ProberFunc(s.probeWebSocket),
+		monitor.TypeHeartbeat:   ProberFunc(s.probeHeartbeat),
+		monitor.TypeTransaction: ProberFunc(s.probeTransaction),
+	}
+	return s
+}
+
+// SetConcurrency sizes the check pool; zero keeps a default. It must be
+// called before the first check.
+func (s *CheckerService) SetConcurrency(workers, perHost, queueSize int) error {
+	if workers < 0 || perHost < 0 || queueSize < 0 {
+		return fmt.Errorf("concurrency limits must not be negative")
+	}
+	if workers > 0 {
+		s.pool.workers = workers
+	}
+	if perHost > 0 {
+		s.pool.perHost = perHost
+	}
+	if queueSize > 0 {
+		s.pool.queueSize = queueSize
+	}
+	return nil
+}
+
+// Stats reports the state of the check queue.
+func (s *CheckerService) Stats() PoolStats {
+	return s.pool.stats()
 }
 
-func NewCheckerService(repo monitor.Repository, logger Logger) *CheckerService {
-	return &CheckerService{
-		repo:   repo,
-		logger: logger,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+// SetJitter spreads runs by delaying each by a random duration up to d,
+// and at most half the time to the monitor's following run.
+func (s *CheckerService) SetJitter(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("jitter must not be negative")
 	}
+	s.jitter = d
+	return nil
 }
 
+// SetLeases makes the checker one of several instances sharing the
+// repository, identified by owner. A due monitor is only checked by the
+// instance holding its lease; leases are renewed every third of ttl, so
+// those of an instance that stops are taken over once ttl has passed.
+func (s *CheckerService) SetLeases(owner string, ttl time.Duration) error {
+	if owner == "" {
+		return fmt.Errorf("lease owner must not be empty")
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("lease TTL must be positive")
+	}
+	s.owner = owner
+	s.leaseTTL = ttl
+	return nil
+}
+
+// Start runs each active monitor on its interval or cron schedule until ctx
+// is done. Monitors are loaded once, retrying until that succeeds; later
+// changes arrive through MonitorSaved and MonitorDeleted. With leases,
+// monitors and maintenance windows changed through other instances are also
+// loaded on every renewal, and the leases are released on return once running checks have
+// finished.
 func (s *CheckerService) Start(ctx context.Context) {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+	defer func() {
+		s.pool.stop()
+		if s.leaseTTL > 0 {
+			s.releaseLeases()
+		}
+	}()
+
+	var retryLoad <-chan time.Time
+	backoff := time.Second
+	if !s.loadMonitors() {
+		retryLoad = time.After(backoff)
+	}
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	var renew <-chan time.Time
+	if s.leaseTTL > 0 {
+		ticker := time.NewTicker(s.leaseTTL / 3)
+		defer ticker.Stop()
+		renew = ticker.C
+	}
 
 	for {
+		if next, ok := s.schedule.next(); ok {
+			timer.Reset(time.Until(next))
+		} else {
+			timer.Reset(time.Hour)
+		}
+
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			s.checkAllMonitors()
+		case <-s.schedule.wake:
+		case <-timer.C:
+			s.runDue(time.Now())
+		case <-retryLoad:
+			retryLoad = nil
+			if !s.loadMonitors() {
+				backoff = min(backoff*2, loadRetryDelay)
+				retryLoad = time.After(backoff)
+			}
+		case <-renew:
+			s.renewLeases()
+			if s.loadMonitors() {
+				retryLoad = nil
+			}
+			s.MaintenanceChanged()
 		}
 	}
 }
 
-func (s *CheckerService) checkAllMonitors() {
-	monitors, err := s.repo.FindAll()
+// loadMonitors schedules the active monitors that are not scheduled yet.
+// After the first load only monitors changed since are read; the window
+// reaches back one lease TTL to allow for clock skew between instances and
+// timestamps stored in seconds. It reports whether the load succeeded.
+func (s *CheckerService) loadMonitors() bool {
+	now := time.Now()
+	var monitors []*monitor.URLMonitor
+	var err error
+	if s.loadedAt.IsZero() {
+		monitors, err = s.repo.FindAll()
+	} else {
+		monitors, err = s.repo.FindUpdatedSince(s.loadedAt.Add(-s.leaseTTL))
+	}
 	if err != nil {
 		log.Printf("Error getting monitors: %v", err)
-		return
+		return false
 	}
+	s.loadedAt = now
 
 	for _, m := range monitors {
+		if !s.schedule.has(m.ID) {
+			s.MonitorSaved(m)
+		}
+	}
+	return true
+}
+
+// claim reports whether this instance may check the monitor, taking its
+// lease when it is free or expired.
+func (s *CheckerService) claim(id string) bool {
+	if s.leaseTTL == 0 {
+		return true
+	}
+	ok, err := s.repo.AcquireLease(id, s.owner, time.Now(), s.leaseTTL)
+	if err != nil {
+		log.Printf("Error acquiring lease on %s: %v", id, err)
+		return false
+	}
+	return ok
+}
+
+func (s *CheckerService) renewLeases() {
+	if err := s.repo.RenewLeases(s.owner, time.Now(), s.leaseTTL); err != nil {
+		log.Printf("Error renewing leases: %v", err)
+	}
+}
+
+func (s *CheckerService) releaseLeases() {
+	if err := s.repo.ReleaseLeases(s.owner); err != nil {
+		log.Printf("Error releasing leases: %v", err)
+	}
+}
+
+// MonitorSaved schedules an active monitor's next run, or unschedules a
+// paused one. Heartbeat monitors are also saved on every ping, which moves
+// their deadline.
+func (s *CheckerService) MonitorSaved(m *monitor.URLMonitor) {
+	if !m.IsActive {
+		s.schedule.remove(m.ID)
+		return
+	}
+
+	now := time.Now()
+	if m.Cron != nil {
+		s.scheduleCron(m, now)
+		return
+	}
+	if m.Type == monitor.TypeHeartbeat {
+		s.schedule.set(m.ID, later(heartbeatCheckAt(m), now))
+		return
+	}
+
+	interval := scheduleInterval(m)
+	next := now
+	if m.LastChecked != nil {
+		next = m.LastChecked.Add(interval)
+	}
+	if next.Before(now) {
+		next = now
+	}
+	s.schedule.set(m.ID, next.Add(s.jitterWithin(interval)))
+}
+
+func (s *CheckerService) MonitorDeleted(id string) {
+	s.schedule.remove(id)
+}
+
+// runDue queues the checks that are due and schedules their next run.
+// Interval runs follow one interval after the previous, so they do not
+// drift by the time checks take; a run that fell more than an interval
+// behind restarts from now. Monitors leased by another instance stay
+// scheduled, so this one takes over when that lease expires. Only deleted
+// monitors are dropped; one that fails to load is retried shortly.
+func (s *CheckerService) runDue(now time.Time) {
+	for id, due := range s.schedule.popDue(now) {
+		m, err := s.repo.FindByID(id)
+		if errors.Is(err, monitor.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Error getting monitor %s: %v", id, err)
+			s.schedule.set(id, now.Add(loadRetryDelay))
+			continue
+		}
 		if !m.IsActive {
 			continue
 		}
 
-		if m.LastChecked != nil && time.Since(*m.LastChecked) < m.Interval {
+		if s.claim(id) {
+			s.pool.submit(m)
+		}
+
+		if m.Cron != nil {
+			s.scheduleCron(m, now)
 			continue
 		}
+		if m.Type == monitor.TypeHeartbeat {
+			s.scheduleHeartbeat(m, now)
+			continue
+		}
+		interval := scheduleInterval(m)
+		next := due.Add(interval)
+		if next.Before(now) {
+			next = now.Add(interval)
+		}
+		s.schedule.set(id, next.Add(s.jitterWithin(interval)))
+	}
+}
+
+// scheduleCron schedules the first cron run after now, with jitter bounded
+// by the gap to the run that follows it.
+func (s *CheckerService) scheduleCron(m *monitor.URLMonitor, now time.Time) {
+	next := m.Cron.Next(now)
+	if next.IsZero() {
+		s.schedule.remove(m.ID)
+		return
+	}
+	s.schedule.set(m.ID, next.Add(s.jitterWithin(m.Cron.Next(next).Sub(next))))
+}
+
+// scheduleHeartbeat checks a heartbeat monitor just after its deadline;
+// once overdue, it is checked again every interval until a ping arrives.
+func (s *CheckerService) scheduleHeartbeat(m *monitor.URLMonitor, now time.Time) {
+	next := heartbeatCheckAt(m)
+	if !next.After(now) {
+		next = now.Add(scheduleInterval(m))
+	}
+	s.schedule.set(m.ID, next)
+}
 
-		go s.checkURL(m)
+func heartbeatCheckAt(m *monitor.URLMonitor) time.Time {
+	return m.HeartbeatDeadline().Add(heartbeatMargin)
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// scheduleInterval guards against monitors without an interval firing in
+// a tight loop.
+func scheduleInterval(m *monitor.URLMonitor) time.Duration {
+	return max(m.Interval, minScheduleInterval)
+}
+
+// jitterWithin returns a random delay of at most half the period.
+func (s *CheckerService) jitterWithin(period time.Duration) time.Duration {
+	limit := min(s.jitter, period/2)
+	if limit <= 0 {
+		return 0
 	}
+	return rand.N(limit)
 }
 
-func (s *CheckerService) checkURL(m *monitor.URLMonitor) {
-	start := time.Now()
-	resp, err := s.client.Get(m.URL)
-	responseTime := time.Since(start)
+// checkURL runs one check of m. During a maintenance window the check is
+// either skipped or flagged, and a flagged failure is not logged since it
+// is expected. A check cancelled through ctx, as on shutdown, records
+// nothing.
+func (s *CheckerService) checkURL(ctx context.Context, m *monitor.URLMonitor) {
+	window := s.maintenanceFor(m, time.Now())
+	if window != nil && window.Mode == monitor.MaintenanceSkip {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, maxCheckDuration)
+	defer cancel()
+	result, err := s.probeWithRetries(checkCtx, m)
+	if ctx.Err() != nil {
+		return
+	}
+	result.InMaintenance = window != nil
 
 	now := time.Now()
 	m.LastChecked = &now
+	result.CheckedAt = now
+
+	if err == nil && m.CertificateStatus(now) == monitor.StatusDown {
+		err = fmt.Errorf("certificate expired at %s", m.Certificate.NotAfter.Format(time.RFC3339))
+	}
 
 	if err != nil {
-		s.logger.LogCheck(m.ID, m.URL, 0, responseTime, err)
+		result.Status = monitor.StatusDown
+		result.Error = err.Error()
+		if result.ErrorKind == "" {
+			result.ErrorKind = monitor.ErrorKindTarget
+		}
 	} else {
-		s.logger.LogCheck(m.ID, m.URL, resp.StatusCode, responseTime, nil)
-		resp.Body.Close()
+		result.Status = monitor.Worse(m.CertificateStatus(now), m.LatencyStatus(result.ResponseTime))
 	}
+	m.LastStatus = result.Status
 
-	s.repo.Update(m)
+	if window == nil || err == nil {
+		s.logger.LogCheck(m.ID, m.URL, result.StatusCode, result.ResponseTime, err)
+	}
+
+	if err := s.results.SaveResult(result); err != nil {
+		log.Printf("Error saving check result for %s: %v", m.ID, err)
+	}
+
+	if err := s.repo.UpdateCheckState(m); err != nil {
+		log.Printf("Error saving check state for %s: %v", m.ID, err)
+	}
+}
+
+// MaintenanceChanged drops the cached maintenance windows; they are
+// loaded again by the next check.
+func (s *CheckerService) MaintenanceChanged() {
+	s.windowsMu.Lock()
+	defer s.windowsMu.Unlock()
+	s.windows = nil
+}
+
+func (s *CheckerService) maintenanceFor(m *monitor.URLMonitor, now time.Time) *monitor.MaintenanceWindow {
+	s.windowsMu.Lock()
+	windows := s.windows
+	if windows == nil {
+		var err error
+		if windows, err = s.repo.FindWindows(); err != nil {
+			s.windowsMu.Unlock()
+			log.Printf("Error getting maintenance windows: %v", err)
+			return nil
+		}
+		s.windows = windows
+	}
+	s.windowsMu.Unlock()
+
+	return monitor.ActiveMaintenance(windows, m, now)
+}
+
+func (s *CheckerService) probeWithRetries(ctx context.Context, m *monitor.URLMonitor) (*monitor.CheckResult, error) {
+	var attemptErrors []string
+
+	for attempt := 1; ; attempt++ {
+		result := monitor.NewCheckResult(m.ID, time.Now())
+		err := s.probeOnce(ctx, m, result)
+		result.Attempts = attempt
+
+		if err == nil || attempt > m.Retries {
+			result.AttemptErrors = attemptErrors
+			return result, err
+		}
+
+		attemptErrors = append(attemptErrors, err.Error())
+		if err := sleepContext(ctx, m.RetryDelay); err != nil {
+			result.AttemptErrors = attemptErrors
+			return result, err
+		}
+	}
+}
+
+func (s *CheckerService) probeOnce(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = monitor.DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return s.probe(ctx, m, result)
+}
+
+func (s *CheckerService) probe(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	monitorType := m.Type
+	if monitorType == "" {
+		monitorType = monitor.TypeHTTP
+	}
+
+	prober, ok := s.probers[monitorType]
+	if !ok {
+		return fmt.Errorf("no prober for monitor type %q", monitorType)
+	}
+	return prober.Probe(ctx, m, result)
+}
+
+// sleepContext waits for d, or returns early with the context's error.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
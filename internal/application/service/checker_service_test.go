@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 	"urlChecker/internal/domain/monitor"
@@ -12,6 +15,7 @@ import (
 
 // Mock Logger для тестов
 type MockLogger struct {
+	mu   sync.Mutex
 	logs []LogEntry
 }
 
@@ -24,6 +28,8 @@ type LogEntry struct {
 }
 
 func (m *MockLogger) LogCheck(monitorID, url string, statusCode int, responseTime time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.logs = append(m.logs, LogEntry{
 		MonitorID:    monitorID,
 		URL:          url,
@@ -33,6 +39,19 @@ func (m *MockLogger) LogCheck(monitorID, url string, statusCode int, responseTim
 	})
 }
 
+func (m *MockLogger) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.logs)
+}
+
+// runFor запускает планировщик на время d
+func runFor(checker *CheckerService, d time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	checker.Start(ctx)
+}
+
 func TestCheckerService_CheckURL_Success(t *testing.T) {
 	// Создаем тестовый HTTP сервер
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -42,13 +61,13 @@ func TestCheckerService_CheckURL_Success(t *testing.T) {
 
 	repo := repository.NewMemoryRepository()
 	mockLogger := &MockLogger{}
-	checker := NewCheckerService(repo, mockLogger)
+	checker := NewCheckerService(repo, repo, mockLogger)
 
 	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
 	repo.Save(m)
 
 	// Проверяем URL
-	checker.checkURL(m)
+	checker.checkURL(context.Background(), m)
 
 	// Проверяем что логирование произошло
 	if len(mockLogger.logs) != 1 {
@@ -62,18 +81,356 @@ func TestCheckerService_CheckURL_Success(t *testing.T) {
 	if log.Error != nil {
 		t.Errorf("expected no error, got %v", log.Error)
 	}
+
+	// Результат проверки должен быть сохранен в истории
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stored result, got %d", len(results))
+	}
+	if results[0].StatusCode != http.StatusOK {
+		t.Errorf("expected stored status 200, got %d", results[0].StatusCode)
+	}
+	if results[0].Status != monitor.StatusUp {
+		t.Errorf("expected status up, got %s", results[0].Status)
+	}
+}
+
+func TestCheckerService_CheckURL_UsesRequestConfig(t *testing.T) {
+	var gotMethod, gotHeader, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHeader = r.Header.Get("X-Api-Key")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	m.SetRequest("POST", map[string]string{"X-Api-Key": "secret"}, `{"ping":true}`)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	if gotMethod != http.MethodPost {
+		t.Errorf("expected method POST, got %s", gotMethod)
+	}
+	if gotHeader != "secret" {
+		t.Errorf("expected X-Api-Key header, got %q", gotHeader)
+	}
+	if gotBody != `{"ping":true}` {
+		t.Errorf("expected request body, got %q", gotBody)
+	}
+}
+
+func TestCheckerService_CheckURL_UnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	// Ответ 500 должен считаться падением, а не нормальной проверкой
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stored result, got %d", len(results))
+	}
+	if results[0].Status != monitor.StatusDown {
+		t.Errorf("expected status down, got %s", results[0].Status)
+	}
+	if results[0].StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status code 500, got %d", results[0].StatusCode)
+	}
+	if mockLogger.logs[0].Error == nil {
+		t.Error("expected logged error for unexpected status")
+	}
+	if m.LastStatus != monitor.StatusDown {
+		t.Errorf("expected monitor last status down, got %s", m.LastStatus)
+	}
+}
+
+func TestCheckerService_CheckURL_CustomExpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	m.SetExpectedStatus("200,404")
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 || results[0].Status != monitor.StatusUp {
+		t.Errorf("expected 404 to be accepted, got %v", results)
+	}
+}
+
+func TestCheckerService_CheckURL_BodyAssertion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"degraded"}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	m.SetAssertions([]monitor.BodyAssertion{
+		{Type: monitor.AssertContains, Value: "status"},
+		{Type: monitor.AssertJSONPath, Path: "$.status", Value: "ok"},
+	})
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	// 200 с "degraded" в теле должен считаться падением
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stored result, got %d", len(results))
+	}
+	if results[0].Status != monitor.StatusDown {
+		t.Errorf("expected status down, got %s", results[0].Status)
+	}
+	if results[0].FailedAssertion != `jsonpath $.status == "ok"` {
+		t.Errorf("expected failing jsonpath assertion, got %q", results[0].FailedAssertion)
+	}
+}
+
+func TestCheckerService_CheckURL_CertificateExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+	checker.client = server.Client()
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	if m.Certificate == nil {
+		t.Fatal("expected certificate to be captured")
+	}
+	if m.Certificate.NotAfter.IsZero() || m.Certificate.Issuer == "" {
+		t.Errorf("expected expiry and issuer, got %+v", m.Certificate)
+	}
+	if m.LastStatus != monitor.StatusUp {
+		t.Errorf("expected status up, got %s", m.LastStatus)
+	}
+
+	// Порог больше срока действия сертификата тестового сервера
+	m.SetCertExpiryDays(m.Certificate.DaysUntilExpiry(time.Now()) + 1)
+	checker.checkURL(context.Background(), m)
+
+	if m.LastStatus != monitor.StatusWarning {
+		t.Errorf("expected status warning, got %s", m.LastStatus)
+	}
+}
+
+func TestCheckerService_CheckURL_Timings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("second"))
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	checker.client = server.Client()
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 || results[0].Timings == nil {
+		t.Fatalf("expected result with timings, got %v", results)
+	}
+
+	timings := results[0].Timings
+	if timings.TCPConnect <= 0 {
+		t.Errorf("expected TCP connect time, got %v", timings.TCPConnect)
+	}
+	if timings.TLSHandshake <= 0 {
+		t.Errorf("expected TLS handshake time, got %v", timings.TLSHandshake)
+	}
+	if timings.TimeToFirstByte < 50*time.Millisecond {
+		t.Errorf("expected time to first byte >= 50ms, got %v", timings.TimeToFirstByte)
+	}
+	if timings.ContentTransfer < 30*time.Millisecond {
+		t.Errorf("expected content transfer >= 30ms, got %v", timings.ContentTransfer)
+	}
+	if results[0].ResponseTime < timings.TimeToFirstByte+timings.ContentTransfer {
+		t.Errorf("expected total %v to cover the phases", results[0].ResponseTime)
+	}
+}
+
+func TestCheckerService_CheckURL_PerMonitorTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+
+	fast := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	fast.SetTimeouts(100*time.Millisecond, 0)
+	repo.Save(fast)
+
+	slow := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	slow.SetTimeouts(2*time.Second, 200*time.Millisecond)
+	repo.Save(slow)
+
+	start := time.Now()
+	checker.checkURL(context.Background(), fast)
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("expected check to stop at the monitor timeout, took %v", elapsed)
+	}
+	if fast.LastStatus != monitor.StatusDown {
+		t.Errorf("expected timed out monitor to be down, got %s", fast.LastStatus)
+	}
+
+	// Ответ пришел, но медленнее порога — статус degraded, а не down
+	checker.checkURL(context.Background(), slow)
+	if slow.LastStatus != monitor.StatusDegraded {
+		t.Errorf("expected slow monitor to be degraded, got %s", slow.LastStatus)
+	}
+}
+
+func TestCheckerService_CheckURL_RetriesBeforeFailure(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Первый запрос падает, повторный проходит
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	m.SetRetryPolicy(2, 10*time.Millisecond)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stored result, got %d", len(results))
+	}
+	if results[0].Status != monitor.StatusUp {
+		t.Errorf("expected status up after retry, got %s", results[0].Status)
+	}
+	if results[0].Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", results[0].Attempts)
+	}
+	if len(results[0].AttemptErrors) != 1 {
+		t.Errorf("expected 1 attempt error, got %v", results[0].AttemptErrors)
+	}
+}
+
+func TestCheckerService_CheckURL_RetriesExhausted(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	m.SetRetryPolicy(2, 0)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 || results[0].Status != monitor.StatusDown || results[0].Attempts != 3 {
+		t.Errorf("expected a single down result after 3 attempts, got %+v", results)
+	}
+}
+
+func TestCheckerService_CheckURL_CancelledDuringRetryDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+
+	m := monitor.NewURLMonitor(server.URL, 1*time.Minute)
+	m.SetRetryPolicy(monitor.MaxRetries, monitor.MaxRetryDelay)
+	repo.Save(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.checkURL(ctx, m)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	// Остановка прерывает ожидание между попытками
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected a cancelled check to return during its retry delay")
+	}
+	if results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{}); len(results) != 0 {
+		t.Errorf("expected a cancelled check to record nothing, got %+v", results)
+	}
 }
 
 func TestCheckerService_CheckURL_Error(t *testing.T) {
 	repo := repository.NewMemoryRepository()
 	mockLogger := &MockLogger{}
-	checker := NewCheckerService(repo, mockLogger)
+	checker := NewCheckerService(repo, repo, mockLogger)
 
 	// Невалидный URL
 	m := monitor.NewURLMonitor("http://invalid-url-that-does-not-exist-12345.com", 1*time.Minute)
 	repo.Save(m)
 
-	checker.checkURL(m)
+	checker.checkURL(context.Background(), m)
 
 	if len(mockLogger.logs) != 1 {
 		t.Errorf("expected 1 log entry, got %d", len(mockLogger.logs))
@@ -83,12 +440,17 @@ func TestCheckerService_CheckURL_Error(t *testing.T) {
 	if log.Error == nil {
 		t.Error("expected error, got nil")
 	}
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 || results[0].Error == "" {
+		t.Errorf("expected stored failed result, got %v", results)
+	}
 }
 
-func TestCheckerService_CheckAllMonitors_SkipsInactive(t *testing.T) {
+func TestCheckerService_Start_SkipsInactive(t *testing.T) {
 	repo := repository.NewMemoryRepository()
 	mockLogger := &MockLogger{}
-	checker := NewCheckerService(repo, mockLogger)
+	checker := NewCheckerService(repo, repo, mockLogger)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -99,21 +461,18 @@ func TestCheckerService_CheckAllMonitors_SkipsInactive(t *testing.T) {
 	m.Pause()
 	repo.Save(m)
 
-	checker.checkAllMonitors()
-
-	// Ждем немного для завершения goroutines
-	time.Sleep(100 * time.Millisecond)
+	runFor(checker, 100*time.Millisecond)
 
 	// Неактивный монитор не должен проверяться
-	if len(mockLogger.logs) != 0 {
-		t.Errorf("expected 0 log entries for inactive monitor, got %d", len(mockLogger.logs))
+	if mockLogger.count() != 0 {
+		t.Errorf("expected 0 log entries for inactive monitor, got %d", mockLogger.count())
 	}
 }
 
-func TestCheckerService_CheckAllMonitors_RespectsInterval(t *testing.T) {
+func TestCheckerService_Start_RespectsInterval(t *testing.T) {
 	repo := repository.NewMemoryRepository()
 	mockLogger := &MockLogger{}
-	checker := NewCheckerService(repo, mockLogger)
+	checker := NewCheckerService(repo, repo, mockLogger)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -125,20 +484,18 @@ func TestCheckerService_CheckAllMonitors_RespectsInterval(t *testing.T) {
 	m.LastChecked = &now
 	repo.Save(m)
 
-	checker.checkAllMonitors()
-
-	time.Sleep(100 * time.Millisecond)
+	runFor(checker, 100*time.Millisecond)
 
 	// Интервал не прошел - не должно быть проверки
-	if len(mockLogger.logs) != 0 {
-		t.Errorf("expected 0 log entries (interval not passed), got %d", len(mockLogger.logs))
+	if mockLogger.count() != 0 {
+		t.Errorf("expected 0 log entries (interval not passed), got %d", mockLogger.count())
 	}
 }
 
 func TestCheckerService_Start_StopsOnContextCancel(t *testing.T) {
 	repo := repository.NewMemoryRepository()
 	mockLogger := &MockLogger{}
-	checker := NewCheckerService(repo, mockLogger)
+	checker := NewCheckerService(repo, repo, mockLogger)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -161,10 +518,10 @@ func TestCheckerService_Start_StopsOnContextCancel(t *testing.T) {
 	}
 }
 
-func TestCheckerService_CheckAllMonitors_MultipleMonitors(t *testing.T) {
+func TestCheckerService_Start_MultipleMonitors(t *testing.T) {
 	repo := repository.NewMemoryRepository()
 	mockLogger := &MockLogger{}
-	checker := NewCheckerService(repo, mockLogger)
+	checker := NewCheckerService(repo, repo, mockLogger)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -180,12 +537,10 @@ func TestCheckerService_CheckAllMonitors_MultipleMonitors(t *testing.T) {
 	repo.Save(m2)
 	repo.Save(m3)
 
-	checker.checkAllMonitors()
-
-	time.Sleep(200 * time.Millisecond)
+	runFor(checker, 200*time.Millisecond)
 
 	// Все 3 монитора должны быть проверены
-	if len(mockLogger.logs) != 3 {
-		t.Errorf("expected 3 log entries, got %d", len(mockLogger.logs))
+	if mockLogger.count() != 3 {
+		t.Errorf("expected 3 log entries, got %d", mockLogger.count())
 	}
 }
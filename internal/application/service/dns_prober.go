@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+type dnsProber struct {
+	dialer *net.Dialer
+}
+
+func newDNSProber() *dnsProber {
+	return &dnsProber{dialer: &net.Dialer{}}
+}
+
+func (p *dnsProber) Probe(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	name, err := monitor.DNSName(m.URL)
+	if err != nil {
+		return err
+	}
+
+	check := m.DNS
+	if check == nil {
+		check = &monitor.DNSCheck{Record: monitor.DNSRecordA}
+	}
+
+	resolver, err := p.resolver(check)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	answers, err := lookup(ctx, resolver, check.Record, name)
+	result.ResponseTime = time.Since(start)
+	if err != nil {
+		return err
+	}
+
+	return check.Verify(answers)
+}
+
+func (p *dnsProber) resolver(check *monitor.DNSCheck) (*net.Resolver, error) {
+	if check.Server == "" {
+		return net.DefaultResolver, nil
+	}
+
+	address, err := check.ServerAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return p.dialer.DialContext(ctx, network, address)
+		},
+	}, nil
+}
+
+func lookup(ctx context.Context, resolver *net.Resolver, recordType monitor.DNSRecordType, name string) ([]string, error) {
+	// A trailing dot keeps resolv.conf search domains out of the query.
+	fqdn := name
+	if fqdn[len(fqdn)-1] != '.' {
+		fqdn += "."
+	}
+
+	switch recordType {
+	case monitor.DNSRecordA, monitor.DNSRecordAAAA:
+		network := "ip4"
+		if recordType == monitor.DNSRecordAAAA {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		answers := make([]string, len(ips))
+		for i, ip := range ips {
+			answers[i] = ip.String()
+		}
+		return answers, nil
+	case monitor.DNSRecordCNAME:
+		cname, err := resolver.LookupCNAME(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		return []string{cname}, nil
+	case monitor.DNSRecordMX:
+		records, err := resolver.LookupMX(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		answers := make([]string, len(records))
+		for i, mx := range records {
+			answers[i] = mx.Host
+		}
+		return answers, nil
+	case monitor.DNSRecordTXT:
+		return resolver.LookupTXT(ctx, fqdn)
+	default:
+		return nil, fmt.Errorf("unsupported DNS record type %q", recordType)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+const (
+	dnsTypeA     = 1
+	dnsTypeCNAME = 5
+	dnsTypeMX    = 15
+	dnsTypeTXT   = 16
+	dnsTypeAAAA  = 28
+)
+
+// startStubDNS отвечает на запросы из records (ключ — "имя/тип"),
+// на остальные возвращает пустой ответ NOERROR.
+func startStubDNS(t *testing.T, records map[string][][]byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := stubDNSResponse(buf[:n], records); resp != nil {
+				conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func stubDNSResponse(query []byte, records map[string][][]byte) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	// Разбираем имя из секции вопроса
+	var labels []string
+	pos := 12
+	for pos < len(query) && query[pos] != 0 {
+		l := int(query[pos])
+		labels = append(labels, string(query[pos+1:pos+1+l]))
+		pos += 1 + l
+	}
+	pos++
+	if pos+4 > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[pos:])
+	question := query[12 : pos+4]
+
+	name := strings.ToLower(strings.Join(labels, "."))
+	answers := records[name+"/"+typeName(qtype)]
+
+	resp := make([]byte, 12, 512)
+	copy(resp, query[:2])
+	binary.BigEndian.PutUint16(resp[2:], 0x8180)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+	binary.BigEndian.PutUint16(resp[6:], uint16(len(answers)))
+	resp = append(resp, question...)
+
+	for _, rdata := range answers {
+		resp = append(resp, 0xC0, 0x0C)
+		resp = binary.BigEndian.AppendUint16(resp, qtype)
+		resp = binary.BigEndian.AppendUint16(resp, 1)
+		resp = binary.BigEndian.AppendUint32(resp, 60)
+		resp = binary.BigEndian.AppendUint16(resp, uint16(len(rdata)))
+		resp = append(resp, rdata...)
+	}
+	return resp
+}
+
+func typeName(qtype uint16) string {
+	switch qtype {
+	case dnsTypeA:
+		return "A"
+	case dnsTypeAAAA:
+		return "AAAA"
+	case dnsTypeCNAME:
+		return "CNAME"
+	case dnsTypeMX:
+		return "MX"
+	case dnsTypeTXT:
+		return "TXT"
+	}
+	return ""
+}
+
+func encodeDNSName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+func newDNSMonitor(repo *repository.MemoryRepository, server, name string, check monitor.DNSCheck) *monitor.URLMonitor {
+	m := monitor.NewURLMonitor("dns://"+name, 1*time.Minute)
+	check.Server = server
+	m.SetDNSCheck(&check)
+	repo.Save(m)
+	return m
+}
+
+func TestCheckerService_CheckURL_DNS(t *testing.T) {
+	server := startStubDNS(t, map[string][][]byte{
+		"example.test/A":         {net.ParseIP("192.0.2.10").To4()},
+		"example.test/MX":        {append([]byte{0, 10}, encodeDNSName("mail.example.test")...)},
+		"example.test/TXT":       {append([]byte{15}, "v=spf1 -all ok."...)},
+		"www.example.test/CNAME": {encodeDNSName("example.test")},
+	})
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+
+	cases := []struct {
+		name  string
+		host  string
+		check monitor.DNSCheck
+		want  monitor.Status
+	}{
+		{"A matches", "example.test", monitor.DNSCheck{Record: "A", Expected: []string{"192.0.2.10"}}, monitor.StatusUp},
+		{"A mismatch", "example.test", monitor.DNSCheck{Record: "A", Expected: []string{"192.0.2.99"}}, monitor.StatusDown},
+		{"MX matches", "example.test", monitor.DNSCheck{Record: "MX", Expected: []string{"MAIL.example.test."}}, monitor.StatusUp},
+		{"TXT matches", "example.test", monitor.DNSCheck{Record: "TXT", Expected: []string{"v=spf1 -all ok."}}, monitor.StatusUp},
+		{"CNAME matches", "www.example.test", monitor.DNSCheck{Record: "CNAME", Expected: []string{"example.test"}}, monitor.StatusUp},
+		{"AAAA missing", "example.test", monitor.DNSCheck{Record: "AAAA"}, monitor.StatusDown},
+	}
+
+	for _, tc := range cases {
+		m := newDNSMonitor(repo, server, tc.host, tc.check)
+
+		checker.checkURL(context.Background(), m)
+
+		results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+		if len(results) != 1 {
+			t.Fatalf("%s: expected 1 stored result, got %d", tc.name, len(results))
+		}
+		if results[0].Status != tc.want {
+			t.Errorf("%s: expected status %s, got %s (%s)", tc.name, tc.want, results[0].Status, results[0].Error)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// probeHeartbeat sends nothing: it only checks that the monitor's pings
+// are on time and the last one did not report failure. The pings are read
+// again since one may have arrived while the check was queued.
+func (s *CheckerService) probeHeartbeat(_ context.Context, m *monitor.URLMonitor, _ *monitor.CheckResult) error {
+	if current, err := s.repo.FindByID(m.ID); err == nil && current.Heartbeat != nil {
+		m.Heartbeat = current.Heartbeat
+	}
+	return m.CheckHeartbeat(time.Now())
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+func TestMonitorService_RecordHeartbeat(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	service := NewMonitorService(repo, repo)
+
+	m, err := service.CreateMonitor(MonitorParams{Type: monitor.TypeHeartbeat, Interval: time.Hour, Grace: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := service.RecordHeartbeat(m.Heartbeat.Token, monitor.PingStart, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := service.RecordHeartbeat(m.Heartbeat.Token, monitor.PingFail, "exit status 1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 || results[0].Status != monitor.StatusDown || results[0].Log != "exit status 1" {
+		t.Errorf("expected one failed run with its log, got %+v", results)
+	}
+
+	if err := service.RecordHeartbeat("unknown", monitor.PingSuccess, ""); err == nil {
+		t.Error("expected error for unknown token")
+	}
+}
+
+func TestHeartbeatProber_MissedPing(t *testing.T) {
+	m := monitor.NewURLMonitor("", time.Minute)
+	m.SetType(monitor.TypeHeartbeat)
+	m.SetHeartbeat(30 * time.Second)
+
+	// Последний пинг был давно — интервал и grace истекли
+	m.RecordPing(monitor.PingSuccess, "", time.Now().Add(-2*time.Minute))
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown || result.Error == "" {
+		t.Errorf("expected down for missed ping, got %s (%s)", result.Status, result.Error)
+	}
+
+	m.RecordPing(monitor.PingSuccess, "", time.Now())
+	if result := checkOnce(t, m); result.Status != monitor.StatusUp {
+		t.Errorf("expected up after a fresh ping, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestHeartbeatProber_PingWhileQueued(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "monitors.db")
+	repo, err := repository.NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	service := NewMonitorService(repo, repo)
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	m, _ := service.CreateMonitor(MonitorParams{Type: monitor.TypeHeartbeat, Interval: time.Minute})
+	old := time.Now().Add(-2 * time.Minute)
+	m.CreatedAt = old
+	repo.Update(m)
+
+	// Проверка загрузила монитор до того, как пришел пинг
+	queued, _ := repo.FindByID(m.ID)
+	if err := service.RecordHeartbeat(m.Heartbeat.Token, monitor.PingSuccess, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	checker.checkURL(context.Background(), queued)
+
+	found, _ := repo.FindByID(m.ID)
+	if found.LastStatus != monitor.StatusUp || found.Heartbeat.LastPing == nil {
+		t.Errorf("expected the ping to survive the check, got %s with last ping %v", found.LastStatus, found.Heartbeat.LastPing)
+	}
+}
+
+func TestCheckerService_SchedulesHeartbeatDeadline(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	service := NewMonitorService(repo, repo)
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	defer checker.pool.stop()
+	service.SetListener(checker)
+
+	m, _ := service.CreateMonitor(MonitorParams{Type: monitor.TypeHeartbeat, Interval: 24 * time.Hour, Grace: 10 * time.Minute})
+	// Ночная задача отчиталась почти сутки назад: срок истекает через минуту
+	m.RecordPing(monitor.PingSuccess, "", time.Now().Add(-24*time.Hour-9*time.Minute))
+	checker.MonitorSaved(m)
+
+	deadline := m.HeartbeatDeadline()
+	if next, _ := checker.schedule.next(); !next.Equal(deadline.Add(heartbeatMargin)) {
+		t.Fatalf("expected a check just after the deadline %v, got %v", deadline, next)
+	}
+
+	// Срок прошел между тиками интервала — пропуск замечен сразу
+	checkAt := deadline.Add(heartbeatMargin)
+	if err := m.CheckHeartbeat(checkAt); err == nil {
+		t.Error("expected the heartbeat to be overdue at the scheduled check")
+	}
+	checker.runDue(checkAt)
+	if next, _ := checker.schedule.next(); !next.Equal(checkAt.Add(24 * time.Hour)) {
+		t.Errorf("expected an overdue heartbeat to be rechecked after an interval, got %v", next)
+	}
+
+	// Пинг переносит срок
+	if err := service.RecordHeartbeat(m.Heartbeat.Token, monitor.PingSuccess, ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if next, _ := checker.schedule.next(); !next.Equal(m.HeartbeatDeadline().Add(heartbeatMargin)) {
+		t.Errorf("expected a ping to move the check to the new deadline, got %v", next)
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+const maxBodySize = 1 << 20
+
+func (s *CheckerService) probeHTTP(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	_, body, err := s.exchange(ctx, m, result)
+	if err != nil || m.Watch == nil {
+		return err
+	}
+	return s.watchContent(m, body, result)
+}
+
+// watchContent stores a snapshot when the watched content changed. The
+// first snapshot of a monitor is a baseline, not a change.
+func (s *CheckerService) watchContent(m *monitor.URLMonitor, body []byte, result *monitor.CheckResult) error {
+	previous := m.Watch.LastHash
+	snapshot, err := m.ObserveContent(body, time.Now())
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	if snapshot == nil {
+		return nil
+	}
+	if err := s.results.SaveSnapshot(snapshot); err != nil {
+		// Keep the old hash so the next check tries to store it again.
+		m.Watch.LastHash = previous
+		return fmt.Errorf("saving snapshot: %w", err)
+	}
+	result.ContentChanged = previous != ""
+	return nil
+}
+
+// exchange sends m's request and verifies the response against the
+// monitor's status rule, redirect policy and assertions. The response is
+// returned with its body already read and closed, for callers that need
+// more than the verdict.
+func (s *CheckerService) exchange(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) (*http.Response, []byte, error) {
+	tracer := &requestTracer{}
+	ctx = httptrace.WithClientTrace(ctx, tracer.clientTrace())
+
+	start := time.Now()
+	resp, err := s.doRequest(ctx, m, result)
+	if err != nil {
+		result.ResponseTime = time.Since(start)
+		result.Timings = tracer.timings(time.Now())
+		if isProxyError(err) {
+			result.ErrorKind = monitor.ErrorKindProxy
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// A rejected OAuth2 token is dropped so the next check fetches a new one.
+	if resp.StatusCode == http.StatusUnauthorized && m.Auth != nil && m.Auth.Type == monitor.AuthOAuth2 {
+		s.tokens.invalidate(m.Auth)
+	}
+
+	// The body is always read so that content transfer time is measured.
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	end := time.Now()
+	result.ResponseTime = end.Sub(start)
+	result.Timings = tracer.timings(end)
+
+	result.StatusCode = resp.StatusCode
+	m.Certificate = leafCertificate(resp)
+
+	if !m.AcceptsStatus(resp.StatusCode) {
+		return resp, body, fmt.Errorf("unexpected status code %d (expected %s)", resp.StatusCode, m.ExpectedStatus)
+	}
+	if err := verifyLocation(m, resp, result); err != nil {
+		return resp, body, err
+	}
+	if readErr != nil {
+		return resp, body, fmt.Errorf("reading body: %w", readErr)
+	}
+
+	return resp, body, verifyBody(m, body, result)
+}
+
+func verifyBody(m *monitor.URLMonitor, body []byte, result *monitor.CheckResult) error {
+	failed, err := m.CheckBody(body)
+	if failed != nil {
+		result.FailedAssertion = failed.String()
+		return fmt.Errorf("assertion failed: %w", err)
+	}
+	return nil
+}
+
+// verifyLocation checks the final URL, or the Location header when redirects
+// are not followed, against the monitor's expected URL.
+func verifyLocation(m *monitor.URLMonitor, resp *http.Response, result *monitor.CheckResult) error {
+	location := resp.Request.URL.String()
+	if !m.FollowRedirects {
+		next, err := resp.Location()
+		if err != nil {
+			location = ""
+		} else {
+			location = next.String()
+			result.RedirectChain = append(result.RedirectChain, location)
+		}
+	}
+
+	if m.ExpectedURL != "" && location != m.ExpectedURL {
+		return fmt.Errorf("redirected to %q, expected %q", location, m.ExpectedURL)
+	}
+	return nil
+}
+
+func (s *CheckerService) doRequest(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) (*http.Response, error) {
+	var body io.Reader
+	if m.Body != "" {
+		body = strings.NewReader(m.Body)
+	}
+
+	method := m.Method
+	if method == "" {
+		method = monitor.DefaultMethod
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range m.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	if err := s.applyAuth(ctx, m, req); err != nil {
+		return nil, err
+	}
+
+	base, err := s.clientFor(m)
+	if err != nil {
+		return nil, err
+	}
+
+	// A shallow copy shares the transport but lets each monitor have its
+	// own redirect policy.
+	client := *base
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if !m.FollowRedirects {
+			return http.ErrUseLastResponse
+		}
+		result.RedirectChain = append(result.RedirectChain, next.URL.String())
+		if len(via) > maxRedirects(m) {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects(m))
+		}
+		return nil
+	}
+
+	return client.Do(req)
+}
+
+// applyAuth authenticates req as m. OAuth2 tokens are requested with the
+// monitor's own client, so the token endpoint is reached through the same
+// TLS profile and proxy as the target.
+func (s *CheckerService) applyAuth(ctx context.Context, m *monitor.URLMonitor, req *http.Request) error {
+	auth := m.Auth
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case monitor.AuthBasic:
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case monitor.AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case monitor.AuthOAuth2:
+		client, err := s.clientFor(m)
+		if err != nil {
+			return err
+		}
+		token, err := s.tokens.token(ctx, client, auth)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	default:
+		return fmt.Errorf("unknown auth type %q", auth.Type)
+	}
+	return nil
+}
+
+func maxRedirects(m *monitor.URLMonitor) int {
+	if m.MaxRedirects <= 0 {
+		return monitor.DefaultMaxRedirects
+	}
+	return m.MaxRedirects
+}
+
+func leafCertificate(resp *http.Response) *monitor.CertificateInfo {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return monitor.NewCertificateInfo(resp.TLS.PeerCertificates[0])
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+func newRedirectServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/sso", http.StatusFound)
+	})
+	mux.HandleFunc("/sso", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/sso/login", http.StatusFound)
+	})
+	mux.HandleFunc("/sso/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("please log in"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func checkOnce(t *testing.T, m *monitor.URLMonitor) *monitor.CheckResult {
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stored result, got %d", len(results))
+	}
+	return results[0]
+}
+
+func TestHTTPProber_FollowsRedirectsAndCapturesChain(t *testing.T) {
+	server := newRedirectServer()
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(server.URL+"/app", time.Minute)
+	m.SetRedirectPolicy(true, 0, server.URL+"/app/home")
+
+	result := checkOnce(t, m)
+
+	// Редирект на SSO не должен выглядеть как здоровый 200
+	if result.Status != monitor.StatusDown {
+		t.Errorf("expected down when landing on SSO, got %s", result.Status)
+	}
+	want := []string{server.URL + "/sso", server.URL + "/sso/login"}
+	if len(result.RedirectChain) != 2 || result.RedirectChain[0] != want[0] || result.RedirectChain[1] != want[1] {
+		t.Errorf("expected chain %v, got %v", want, result.RedirectChain)
+	}
+}
+
+func TestHTTPProber_DoesNotFollowRedirects(t *testing.T) {
+	server := newRedirectServer()
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(server.URL+"/app", time.Minute)
+	m.SetRedirectPolicy(false, 0, server.URL+"/sso")
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+	}
+	if result.StatusCode != http.StatusFound {
+		t.Errorf("expected status 302, got %d", result.StatusCode)
+	}
+	if len(result.RedirectChain) != 1 || result.RedirectChain[0] != server.URL+"/sso" {
+		t.Errorf("expected Location in chain, got %v", result.RedirectChain)
+	}
+}
+
+func TestHTTPProber_MaxRedirects(t *testing.T) {
+	server := newRedirectServer()
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(server.URL+"/app", time.Minute)
+	m.SetRedirectPolicy(true, 1, "")
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown {
+		t.Errorf("expected down after exceeding max redirects, got %s", result.Status)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// requestTracer collects phase timestamps from httptrace. Callbacks may run
+// on different goroutines (e.g. parallel dials), hence the mutex.
+type requestTracer struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+}
+
+func (t *requestTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart, false) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone, true) },
+		ConnectStart: func(string, string) {
+			t.mark(&t.connectStart, false)
+		},
+		ConnectDone: func(string, string, error) {
+			t.mark(&t.connectDone, true)
+		},
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart, false) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mark(&t.tlsDone, true)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mark(&t.wroteRequest, true)
+		},
+		GotFirstResponseByte: func() { t.mark(&t.firstByte, false) },
+	}
+}
+
+// mark records the current time. Start events keep the earliest value and
+// done events the latest, so retried dials cover the whole phase.
+func (t *requestTracer) mark(field *time.Time, latest bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if field.IsZero() || latest {
+		*field = time.Now()
+	}
+}
+
+func (t *requestTracer) timings(end time.Time) *monitor.Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &monitor.Timings{
+		DNSLookup:       between(t.dnsStart, t.dnsDone),
+		TCPConnect:      between(t.connectStart, t.connectDone),
+		TLSHandshake:    between(t.tlsStart, t.tlsDone),
+		TimeToFirstByte: between(t.wroteRequest, t.firstByte),
+		ContentTransfer: between(t.firstByte, end),
+	}
+}
+
+func between(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+func newLeasedChecker(t *testing.T, dbPath, owner string, ttl time.Duration) *CheckerService {
+	repo, err := repository.NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	if err := checker.SetLeases(owner, ttl); err != nil {
+		t.Fatalf("failed to set leases: %v", err)
+	}
+	t.Cleanup(checker.pool.stop)
+	return checker
+}
+
+func waitForHits(t *testing.T, hits *atomic.Int32, want int32) {
+	deadline := time.Now().Add(2 * time.Second)
+	for hits.Load() < want && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Даем лишним проверкам, если они есть, время дойти до сервера
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestCheckerService_LeasesCheckEachMonitorOnce(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	dbPath := filepath.Join(t.TempDir(), "monitors.db")
+	var checkers []*CheckerService
+	for _, owner := range []string{"a", "b", "c"} {
+		checkers = append(checkers, newLeasedChecker(t, dbPath, owner, time.Minute))
+	}
+
+	const monitors = 5
+	for range monitors {
+		m := monitor.NewURLMonitor(server.URL, time.Minute)
+		checkers[0].repo.Save(m)
+	}
+
+	// Все экземпляры одновременно считают все мониторы просроченными
+	var wg sync.WaitGroup
+	for _, checker := range checkers {
+		checker.loadMonitors()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checker.runDue(time.Now().Add(time.Second))
+		}()
+	}
+	wg.Wait()
+
+	waitForHits(t, &hits, monitors)
+	if got := hits.Load(); got != monitors {
+		t.Errorf("expected each of %d monitors to be checked once, got %d checks", monitors, got)
+	}
+}
+
+func TestCheckerService_LeaseTakenOverAfterExpiry(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	const ttl = 300 * time.Millisecond
+	dbPath := filepath.Join(t.TempDir(), "monitors.db")
+	dead := newLeasedChecker(t, dbPath, "dead", ttl)
+	standby := newLeasedChecker(t, dbPath, "standby", ttl)
+
+	m := monitor.NewURLMonitor(server.URL, time.Minute)
+	dead.repo.Save(m)
+
+	dead.schedule.set(m.ID, time.Now())
+	dead.runDue(time.Now())
+	waitForHits(t, &hits, 1)
+
+	// Экземпляр "dead" больше не продлевает аренду
+	standby.schedule.set(m.ID, time.Now())
+	standby.runDue(time.Now())
+	waitForHits(t, &hits, 2)
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected standby to leave a live lease alone, got %d checks", got)
+	}
+
+	time.Sleep(ttl)
+	standby.schedule.set(m.ID, time.Now())
+	standby.runDue(time.Now())
+	waitForHits(t, &hits, 2)
+	if got := hits.Load(); got != 2 {
+		t.Errorf("expected standby to take over the expired lease, got %d checks", got)
+	}
+}
+
+func TestCheckerService_StartReleasesLeases(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "monitors.db")
+	first := newLeasedChecker(t, dbPath, "first", time.Minute)
+	second := newLeasedChecker(t, dbPath, "second", time.Minute)
+
+	m := monitor.NewURLMonitor("https://example.invalid", time.Minute)
+	m.Pause()
+	first.repo.Save(m)
+	if ok := first.claim(m.ID); !ok {
+		t.Fatal("expected first to claim the monitor")
+	}
+
+	runFor(first, 10*time.Millisecond)
+
+	// После остановки аренда освобождается сразу, без ожидания истечения
+	if !second.claim(m.ID) {
+		t.Error("expected second to claim the monitor released by first")
+	}
+}
+
+func TestCheckerService_LoadsMonitorsChangedElsewhere(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "monitors.db")
+	checker := newLeasedChecker(t, dbPath, "a", time.Minute)
+	other := newLeasedChecker(t, dbPath, "b", time.Minute)
+
+	old := monitor.NewURLMonitor("https://old.example", time.Minute)
+	old.Pause()
+	checker.repo.Save(old)
+	checker.loadMonitors()
+	if checker.schedule.len() != 0 {
+		t.Fatalf("expected a paused monitor not to be scheduled, got %d", checker.schedule.len())
+	}
+
+	// Монитор создан и другой возобновлен через второй экземпляр
+	created := monitor.NewURLMonitor("https://new.example", time.Minute)
+	other.repo.Save(created)
+	old.Resume()
+	other.repo.Update(old)
+
+	checker.loadMonitors()
+	if !checker.schedule.has(created.ID) || !checker.schedule.has(old.ID) {
+		t.Errorf("expected monitors changed through another instance to be scheduled")
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// MaintenanceParams describes a maintenance window to create or replace.
+// Recurring windows set Cron and Duration, one-off windows Start and End.
+type MaintenanceParams struct {
+	Name       string
+	Mode       monitor.MaintenanceMode
+	MonitorIDs []string
+	Tags       []string
+	Start      time.Time
+	End        time.Time
+	Cron       string
+	TimeZone   string
+	Duration   time.Duration
+}
+
+func (s *MonitorService) CreateMaintenance(p MaintenanceParams) (*monitor.MaintenanceWindow, error) {
+	w := monitor.NewMaintenanceWindow(p.Name)
+	if err := applyMaintenanceParams(w, p); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveWindow(w); err != nil {
+		return nil, err
+	}
+	s.maintenanceChanged()
+	return w, nil
+}
+
+func (s *MonitorService) GetMaintenance(id string) (*monitor.MaintenanceWindow, error) {
+	return s.repo.FindWindow(id)
+}
+
+func (s *MonitorService) GetAllMaintenance() ([]*monitor.MaintenanceWindow, error) {
+	return s.repo.FindWindows()
+}
+
+func (s *MonitorService) UpdateMaintenance(id string, p MaintenanceParams) error {
+	w, err := s.repo.FindWindow(id)
+	if err != nil {
+		return err
+	}
+	w.Name = p.Name
+	if err := applyMaintenanceParams(w, p); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateWindow(w); err != nil {
+		return err
+	}
+	s.maintenanceChanged()
+	return nil
+}
+
+func (s *MonitorService) DeleteMaintenance(id string) error {
+	if err := s.repo.DeleteWindow(id); err != nil {
+		return err
+	}
+	s.maintenanceChanged()
+	return nil
+}
+
+func (s *MonitorService) maintenanceChanged() {
+	if s.listener != nil {
+		s.listener.MaintenanceChanged()
+	}
+}
+
+func applyMaintenanceParams(w *monitor.MaintenanceWindow, p MaintenanceParams) error {
+	if err := w.SetMode(p.Mode); err != nil {
+		return err
+	}
+	if err := w.SetScope(p.MonitorIDs, p.Tags); err != nil {
+		return err
+	}
+
+	var recurrence *monitor.CronSchedule
+	if p.Cron == "" && p.TimeZone != "" {
+		return fmt.Errorf("%w: time zone needs a cron expression", monitor.ErrInvalidConfig)
+	}
+	if p.Cron != "" {
+		recurrence = &monitor.CronSchedule{Expression: p.Cron, TimeZone: p.TimeZone}
+	}
+	return w.SetTimes(p.Start, p.End, recurrence, p.Duration)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+func TestCheckerService_Maintenance(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		mode       monitor.MaintenanceMode
+		wantResult bool
+	}{
+		{monitor.MaintenanceSkip, false},
+		{monitor.MaintenanceFlag, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			repo := repository.NewMemoryRepository()
+			mockLogger := &MockLogger{}
+			checker := NewCheckerService(repo, repo, mockLogger)
+			service := NewMonitorService(repo, repo)
+
+			m, _ := service.CreateMonitor(MonitorParams{URL: server.URL, Interval: time.Minute, Tags: []string{"checkout"}})
+			_, err := service.CreateMaintenance(MaintenanceParams{
+				Name:  "deploy",
+				Mode:  tt.mode,
+				Tags:  []string{"checkout"},
+				Start: time.Now().Add(-time.Minute),
+				End:   time.Now().Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			checker.checkURL(context.Background(), m)
+
+			results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+			if tt.wantResult != (len(results) == 1) {
+				t.Fatalf("expected result stored: %v, got %d results", tt.wantResult, len(results))
+			}
+			if tt.wantResult && (!results[0].InMaintenance || results[0].Status != monitor.StatusDown) {
+				t.Errorf("expected a flagged failure, got %+v", results[0])
+			}
+			// Ошибки во время обслуживания не попадают в лог
+			if mockLogger.count() != 0 {
+				t.Errorf("expected no logged errors during maintenance, got %d", mockLogger.count())
+			}
+		})
+	}
+}
+
+func TestMonitorService_MaintenanceCRUD(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	service := NewMonitorService(repo, repo)
+
+	w, err := service.CreateMaintenance(MaintenanceParams{
+		Name:     "weekly",
+		Tags:     []string{"db"},
+		Cron:     "0 3 * * SUN",
+		TimeZone: "Europe/Kyiv",
+		Duration: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if w.Mode != monitor.MaintenanceFlag || w.Recurrence == nil {
+		t.Errorf("expected recurring flag window, got %+v", w)
+	}
+
+	if err := service.UpdateMaintenance(w.ID, MaintenanceParams{Name: "weekly", Tags: []string{"db"}, TimeZone: "UTC"}); err == nil {
+		t.Error("expected error for time zone without cron")
+	}
+
+	service.DeleteMaintenance(w.ID)
+	if _, err := service.GetMaintenance(w.ID); err == nil {
+		t.Error("expected window to be deleted")
+	}
+}
+
+// countingRepo считает чтения окон обслуживания
+type countingRepo struct {
+	*repository.MemoryRepository
+	windowReads int
+}
+
+func (r *countingRepo) FindWindows() ([]*monitor.MaintenanceWindow, error) {
+	r.windowReads++
+	return r.MemoryRepository.FindWindows()
+}
+
+func TestCheckerService_CachesMaintenanceWindows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	repo := &countingRepo{MemoryRepository: repository.NewMemoryRepository()}
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	service := NewMonitorService(repo, repo)
+	service.SetListener(checker)
+
+	m, _ := service.CreateMonitor(MonitorParams{URL: server.URL, Interval: time.Minute})
+	checker.checkURL(context.Background(), m)
+	checker.checkURL(context.Background(), m)
+	if repo.windowReads != 1 {
+		t.Errorf("expected windows to be read once, got %d reads", repo.windowReads)
+	}
+
+	// Новое окно сбрасывает кеш, и следующая проверка его учитывает
+	service.CreateMaintenance(MaintenanceParams{
+		Name:       "deploy",
+		Mode:       monitor.MaintenanceSkip,
+		MonitorIDs: []string{m.ID},
+		Start:      time.Now().Add(-time.Minute),
+		End:        time.Now().Add(time.Hour),
+	})
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 2 {
+		t.Errorf("expected the check in the new window to be skipped, got %d results", len(results))
+	}
+	if repo.windowReads != 2 {
+		t.Errorf("expected windows to be reloaded after a change, got %d reads", repo.windowReads)
+	}
+}
@@ -1,22 +1,107 @@
 package service
 
 import (
+	"fmt"
 	"time"
 	"urlChecker/internal/domain/monitor"
 )
 
+const (
+	DefaultMinInterval = 10 * time.Second
+	DefaultMaxInterval = 24 * time.Hour
+)
+
 type MonitorService struct {
-	repo monitor.Repository
+	repo        monitor.Repository
+	results     monitor.ResultRepository
+	listener    MonitorListener
+	minInterval time.Duration
+	maxInterval time.Duration
+}
+
+// MonitorListener is told about monitors and maintenance windows changed
+// through MonitorService, after the change is stored.
+type MonitorListener interface {
+	MonitorSaved(m *monitor.URLMonitor)
+	MonitorDeleted(id string)
+	MaintenanceChanged()
+}
+
+func NewMonitorService(repo monitor.Repository, results monitor.ResultRepository) *MonitorService {
+	return &MonitorService{
+		repo:        repo,
+		results:     results,
+		minInterval: DefaultMinInterval,
+		maxInterval: DefaultMaxInterval,
+	}
+}
+
+// SetIntervalBounds limits the intervals monitors may use; zero keeps a
+// default.
+func (s *MonitorService) SetIntervalBounds(min, max time.Duration) error {
+	if min < 0 || max < 0 {
+		return fmt.Errorf("interval bounds must not be negative")
+	}
+	if min > 0 {
+		s.minInterval = min
+	}
+	if max > 0 {
+		s.maxInterval = max
+	}
+	if s.minInterval > s.maxInterval {
+		return fmt.Errorf("minimum interval %s exceeds maximum %s", s.minInterval, s.maxInterval)
+	}
+	return nil
 }
 
-func NewMonitorService(repo monitor.Repository) *MonitorService {
-	return &MonitorService{repo: repo}
+func (s *MonitorService) SetListener(listener MonitorListener) {
+	s.listener = listener
 }
 
-func (s *MonitorService) CreateMonitor(url string, intervalMinutes int) (*monitor.URLMonitor, error) {
-	m := monitor.NewURLMonitor(url, time.Duration(intervalMinutes)*time.Minute)
-	err := s.repo.Save(m)
-	return m, err
+// MonitorParams describes a monitor to create or replace. Nil pointer fields
+// fall back to the defaults of a new monitor.
+type MonitorParams struct {
+	Type            monitor.Type
+	URL             string
+	Interval        time.Duration
+	Cron            string
+	TimeZone        string
+	Tags            []string
+	Method          string
+	Headers         map[string]string
+	Body            string
+	Auth            *monitor.AuthConfig
+	TLS             *monitor.TLSConfig
+	Proxy           string
+	ExpectedStatus  string
+	Assertions      []monitor.BodyAssertion
+	CertExpiryDays  *int
+	DNS             *monitor.DNSCheck
+	Steps           []monitor.TransactionStep
+	Grace           time.Duration
+	Watch           *monitor.Watch
+	Timeout         time.Duration
+	SlowThreshold   time.Duration
+	Retries         int
+	RetryDelay      time.Duration
+	FollowRedirects *bool
+	MaxRedirects    int
+	ExpectedURL     string
+}
+
+func (s *MonitorService) CreateMonitor(p MonitorParams) (*monitor.URLMonitor, error) {
+	if err := s.checkInterval(p); err != nil {
+		return nil, err
+	}
+	m := monitor.NewURLMonitor(p.URL, p.Interval)
+	if err := applyParams(m, p); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(m); err != nil {
+		return nil, err
+	}
+	s.saved(m)
+	return m, nil
 }
 
 func (s *MonitorService) GetMonitor(id string) (*monitor.URLMonitor, error) {
@@ -27,17 +112,97 @@ func (s *MonitorService) GetAllMonitors() ([]*monitor.URLMonitor, error) {
 	return s.repo.FindAll()
 }
 
-func (s *MonitorService) UpdateMonitor(id, url string, intervalMinutes int) error {
+func (s *MonitorService) GetResults(id string, from, to time.Time) ([]*monitor.CheckResult, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.results.FindResults(id, from, to)
+}
+
+func (s *MonitorService) GetSnapshots(id string) ([]*monitor.Snapshot, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.results.FindSnapshots(id)
+}
+
+func (s *MonitorService) GetSnapshot(id string, snapshotID int64) (*monitor.Snapshot, error) {
+	return s.results.FindSnapshot(id, snapshotID)
+}
+
+// DiffSnapshots returns the unified diff between two snapshots of a
+// monitor. A zero to means the latest snapshot and a zero from the one
+// captured before to.
+func (s *MonitorService) DiffSnapshots(id string, fromID, toID int64) (string, error) {
+	snapshots, err := s.GetSnapshots(id)
+	if err != nil {
+		return "", err
+	}
+
+	if toID == 0 && len(snapshots) > 0 {
+		toID = snapshots[len(snapshots)-1].ID
+	}
+	if fromID == 0 {
+		for _, snapshot := range snapshots {
+			if snapshot.ID < toID {
+				fromID = snapshot.ID
+			}
+		}
+	}
+
+	from, err := s.results.FindSnapshot(id, fromID)
+	if err != nil {
+		return "", err
+	}
+	to, err := s.results.FindSnapshot(id, toID)
+	if err != nil {
+		return "", err
+	}
+	return monitor.UnifiedDiff(from, to), nil
+}
+
+// RecordHeartbeat applies a ping sent to a heartbeat monitor's token.
+func (s *MonitorService) RecordHeartbeat(token string, kind monitor.PingKind, log string) error {
+	m, err := s.repo.FindByHeartbeatToken(token)
+	if err != nil {
+		return err
+	}
+
+	if result := m.RecordPing(kind, log, time.Now()); result != nil {
+		if err := s.results.SaveResult(result); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.UpdateHeartbeat(m); err != nil {
+		return err
+	}
+	s.saved(m)
+	return nil
+}
+
+func (s *MonitorService) UpdateMonitor(id string, p MonitorParams) error {
 	m, err := s.repo.FindByID(id)
 	if err != nil {
 		return err
 	}
-	m.Update(url, time.Duration(intervalMinutes)*time.Minute)
-	return s.repo.Update(m)
+	if err := s.checkInterval(p); err != nil {
+		return err
+	}
+	m.Update(p.URL, p.Interval)
+	if err := applyParams(m, p); err != nil {
+		return err
+	}
+	return s.update(m)
 }
 
 func (s *MonitorService) DeleteMonitor(id string) error {
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if s.listener != nil {
+		s.listener.MonitorDeleted(id)
+	}
+	return nil
 }
 
 func (s *MonitorService) PauseMonitor(id string) error {
@@ -46,7 +211,7 @@ func (s *MonitorService) PauseMonitor(id string) error {
 		return err
 	}
 	m.Pause()
-	return s.repo.Update(m)
+	return s.update(m)
 }
 
 func (s *MonitorService) ResumeMonitor(id string) error {
@@ -55,5 +220,96 @@ func (s *MonitorService) ResumeMonitor(id string) error {
 		return err
 	}
 	m.Resume()
-	return s.repo.Update(m)
+	return s.update(m)
+}
+
+func (s *MonitorService) update(m *monitor.URLMonitor) error {
+	if err := s.repo.Update(m); err != nil {
+		return err
+	}
+	s.saved(m)
+	return nil
+}
+
+func (s *MonitorService) saved(m *monitor.URLMonitor) {
+	if s.listener != nil {
+		s.listener.MonitorSaved(m)
+	}
+}
+
+// checkInterval enforces the configured bounds. Cron monitors may omit the
+// interval since their schedule replaces it.
+func (s *MonitorService) checkInterval(p MonitorParams) error {
+	if p.Interval == 0 && p.Cron != "" {
+		return nil
+	}
+	if p.Interval%time.Second != 0 {
+		return fmt.Errorf("%w: interval %s must be a whole number of seconds", monitor.ErrInvalidConfig, p.Interval)
+	}
+	if p.Interval < s.minInterval || p.Interval > s.maxInterval {
+		return fmt.Errorf("%w: interval %s is outside the allowed range %s to %s", monitor.ErrInvalidConfig, p.Interval, s.minInterval, s.maxInterval)
+	}
+	return nil
+}
+
+func applyParams(m *monitor.URLMonitor, p MonitorParams) error {
+	if err := m.SetType(p.Type); err != nil {
+		return err
+	}
+	if err := m.SetRequest(p.Method, p.Headers, p.Body); err != nil {
+		return err
+	}
+	if err := m.SetAuth(p.Auth); err != nil {
+		return err
+	}
+	if err := m.SetTLSConfig(p.TLS); err != nil {
+		return err
+	}
+	if err := m.SetProxy(p.Proxy); err != nil {
+		return err
+	}
+	if err := m.SetExpectedStatus(p.ExpectedStatus); err != nil {
+		return err
+	}
+	if err := m.SetAssertions(p.Assertions); err != nil {
+		return err
+	}
+	if err := m.SetDNSCheck(p.DNS); err != nil {
+		return err
+	}
+	if err := m.SetSteps(p.Steps); err != nil {
+		return err
+	}
+	if err := m.SetHeartbeat(p.Grace); err != nil {
+		return err
+	}
+	if err := m.SetWatch(p.Watch); err != nil {
+		return err
+	}
+	if err := m.SetCron(p.Cron, p.TimeZone); err != nil {
+		return err
+	}
+	if err := m.SetTags(p.Tags); err != nil {
+		return err
+	}
+	if err := m.SetTimeouts(p.Timeout, p.SlowThreshold); err != nil {
+		return err
+	}
+	if err := m.SetRetryPolicy(p.Retries, p.RetryDelay); err != nil {
+		return err
+	}
+
+	follow := true
+	if p.FollowRedirects != nil {
+		follow = *p.FollowRedirects
+	}
+	if err := m.SetRedirectPolicy(follow, p.MaxRedirects, p.ExpectedURL); err != nil {
+		return err
+	}
+
+	certExpiryDays := monitor.DefaultCertExpiryDays
+	if p.CertExpiryDays != nil {
+		certExpiryDays = *p.CertExpiryDays
+	}
+	return m.SetCertExpiryDays(certExpiryDays)
 }
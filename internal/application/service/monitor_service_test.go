@@ -1,16 +1,18 @@
 package service
 
 import (
+	"errors"
 	"testing"
 	"time"
+	"urlChecker/internal/domain/monitor"
 	"urlChecker/internal/infrastructure/repository"
 )
 
 func TestMonitorService_CreateMonitor(t *testing.T) {
 	repo := repository.NewMemoryRepository()
-	service := NewMonitorService(repo)
+	service := NewMonitorService(repo, repo)
 
-	m, err := service.CreateMonitor("https://example.com", 5)
+	m, err := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -25,8 +27,8 @@ func TestMonitorService_CreateMonitor(t *testing.T) {
 
 func TestMonitorService_GetMonitor(t *testing.T) {
 	repo := repository.NewMemoryRepository()
-	service := NewMonitorService(repo)
-	m, _ := service.CreateMonitor("https://example.com", 5)
+	service := NewMonitorService(repo, repo)
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
 
 	found, err := service.GetMonitor(m.ID)
 
@@ -40,9 +42,9 @@ func TestMonitorService_GetMonitor(t *testing.T) {
 
 func TestMonitorService_GetAllMonitors(t *testing.T) {
 	repo := repository.NewMemoryRepository()
-	service := NewMonitorService(repo)
-	service.CreateMonitor("https://example1.com", 5)
-	service.CreateMonitor("https://example2.com", 10)
+	service := NewMonitorService(repo, repo)
+	service.CreateMonitor(MonitorParams{URL: "https://example1.com", Interval: 5 * time.Minute})
+	service.CreateMonitor(MonitorParams{URL: "https://example2.com", Interval: 10 * time.Minute})
 
 	all, err := service.GetAllMonitors()
 
@@ -56,10 +58,10 @@ func TestMonitorService_GetAllMonitors(t *testing.T) {
 
 func TestMonitorService_UpdateMonitor(t *testing.T) {
 	repo := repository.NewMemoryRepository()
-	service := NewMonitorService(repo)
-	m, _ := service.CreateMonitor("https://example.com", 5)
+	service := NewMonitorService(repo, repo)
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
 
-	err := service.UpdateMonitor(m.ID, "https://updated.com", 10)
+	err := service.UpdateMonitor(m.ID, MonitorParams{URL: "https://updated.com", Interval: 10 * time.Minute})
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -73,8 +75,8 @@ func TestMonitorService_UpdateMonitor(t *testing.T) {
 
 func TestMonitorService_DeleteMonitor(t *testing.T) {
 	repo := repository.NewMemoryRepository()
-	service := NewMonitorService(repo)
-	m, _ := service.CreateMonitor("https://example.com", 5)
+	service := NewMonitorService(repo, repo)
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
 
 	err := service.DeleteMonitor(m.ID)
 
@@ -90,8 +92,8 @@ func TestMonitorService_DeleteMonitor(t *testing.T) {
 
 func TestMonitorService_PauseMonitor(t *testing.T) {
 	repo := repository.NewMemoryRepository()
-	service := NewMonitorService(repo)
-	m, _ := service.CreateMonitor("https://example.com", 5)
+	service := NewMonitorService(repo, repo)
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
 
 	err := service.PauseMonitor(m.ID)
 
@@ -107,8 +109,8 @@ func TestMonitorService_PauseMonitor(t *testing.T) {
 
 func TestMonitorService_ResumeMonitor(t *testing.T) {
 	repo := repository.NewMemoryRepository()
-	service := NewMonitorService(repo)
-	m, _ := service.CreateMonitor("https://example.com", 5)
+	service := NewMonitorService(repo, repo)
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
 	service.PauseMonitor(m.ID)
 
 	err := service.ResumeMonitor(m.ID)
@@ -122,3 +124,88 @@ func TestMonitorService_ResumeMonitor(t *testing.T) {
 		t.Error("expected monitor to be active")
 	}
 }
+
+func TestMonitorService_GetResults(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	service := NewMonitorService(repo, repo)
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
+
+	repo.SaveResult(monitor.NewCheckResult(m.ID, time.Now()))
+
+	results, err := service.GetResults(m.ID, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected 1 result, got %d", len(results))
+	}
+
+	_, err = service.GetResults("nonexistent", time.Time{}, time.Time{})
+	if err == nil {
+		t.Error("expected error for unknown monitor")
+	}
+}
+
+func TestMonitorService_CreateMonitor_WithRequest(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	service := NewMonitorService(repo, repo)
+
+	m, err := service.CreateMonitor(MonitorParams{
+		URL:      "https://example.com/health",
+		Interval: 5 * time.Minute,
+		Method:   "POST",
+		Headers:  map[string]string{"Accept": "application/json"},
+		Body:     `{"ping":true}`,
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if m.Method != "POST" || m.Headers["Accept"] != "application/json" || m.Body != `{"ping":true}` {
+		t.Errorf("expected request config to be applied, got %+v", m)
+	}
+
+	_, err = service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute, Method: "BREW"})
+	if !errors.Is(err, monitor.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestMonitorService_IntervalBounds(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	service := NewMonitorService(repo, repo)
+
+	m, err := service.CreateMonitor(MonitorParams{URL: "https://example.com/login", Interval: 15 * time.Second})
+	if err != nil || m.Interval != 15*time.Second {
+		t.Fatalf("expected a 15s monitor, got %v (%v)", m, err)
+	}
+
+	invalid := []MonitorParams{
+		{URL: "https://example.com"},
+		{URL: "https://example.com", Interval: 5 * time.Second},
+		{URL: "https://example.com", Interval: 48 * time.Hour},
+		{URL: "https://example.com", Interval: 15500 * time.Millisecond},
+	}
+	for _, p := range invalid {
+		if _, err := service.CreateMonitor(p); !errors.Is(err, monitor.ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for interval %s, got %v", p.Interval, err)
+		}
+	}
+	if err := service.UpdateMonitor(m.ID, MonitorParams{URL: m.URL, Interval: time.Second}); !errors.Is(err, monitor.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig on update, got %v", err)
+	}
+
+	// Для cron интервал не обязателен
+	if _, err := service.CreateMonitor(MonitorParams{URL: "https://example.com", Cron: "@hourly"}); err != nil {
+		t.Errorf("expected cron monitor without interval, got %v", err)
+	}
+
+	service.SetIntervalBounds(time.Second, 0)
+	if _, err := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Second}); err != nil {
+		t.Errorf("expected 5s to be allowed after lowering the minimum, got %v", err)
+	}
+	if err := service.SetIntervalBounds(time.Hour, time.Minute); err == nil {
+		t.Error("expected error when the minimum exceeds the maximum")
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// tokenRefreshMargin renews cached tokens slightly before they expire so a
+// check never goes out with a token that dies in flight.
+const tokenRefreshMargin = 30 * time.Second
+
+type cachedToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// tokenFetch is a token request in progress, shared by the checks waiting
+// for the same token.
+type tokenFetch struct {
+	done  chan struct{}
+	token cachedToken
+	err   error
+}
+
+// tokenCache keeps OAuth2 client-credentials tokens per token URL,
+// credentials and scope set, so monitors sharing credentials share a
+// token. Concurrent checks needing the same token share one request, and
+// a slow token endpoint only holds up the checks that use it.
+type tokenCache struct {
+	mu       sync.Mutex
+	tokens   map[string]cachedToken
+	fetching map[string]*tokenFetch
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		tokens:   make(map[string]cachedToken),
+		fetching: make(map[string]*tokenFetch),
+	}
+}
+
+func (c *tokenCache) token(ctx context.Context, client *http.Client, auth *monitor.AuthConfig) (string, error) {
+	key := tokenCacheKey(auth)
+
+	c.mu.Lock()
+	if cached, ok := c.tokens[key]; ok && time.Now().Before(cached.expiresAt) {
+		c.mu.Unlock()
+		return cached.value, nil
+	}
+	if fetch, ok := c.fetching[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-fetch.done:
+			return fetch.token.value, fetch.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	fetch := &tokenFetch{done: make(chan struct{})}
+	c.fetching[key] = fetch
+	c.mu.Unlock()
+
+	fetch.token, fetch.err = fetchToken(ctx, client, auth)
+
+	c.mu.Lock()
+	delete(c.fetching, key)
+	if fetch.err == nil {
+		c.tokens[key] = fetch.token
+	}
+	c.mu.Unlock()
+	close(fetch.done)
+
+	return fetch.token.value, fetch.err
+}
+
+func (c *tokenCache) invalidate(auth *monitor.AuthConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, tokenCacheKey(auth))
+}
+
+// tokenCacheKey includes a hash of the secret so that a monitor with a
+// wrong or revoked secret never reuses a token issued for a valid one.
+func tokenCacheKey(auth *monitor.AuthConfig) string {
+	secret := sha256.Sum256([]byte(auth.ClientSecret))
+	return strings.Join([]string{auth.TokenURL, auth.ClientID, hex.EncodeToString(secret[:]), strings.Join(auth.Scopes, " ")}, "\x00")
+}
+
+func fetchToken(ctx context.Context, client *http.Client, auth *monitor.AuthConfig) (cachedToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, auth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return cachedToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(auth.ClientID), url.QueryEscape(auth.ClientSecret))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("oauth2 token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return cachedToken{}, fmt.Errorf("oauth2 token request: unexpected status code %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return cachedToken{}, fmt.Errorf("oauth2 token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return cachedToken{}, fmt.Errorf("oauth2 token response has no access_token")
+	}
+
+	// Tokens without expires_in are reused for an hour.
+	lifetime := time.Hour
+	if payload.ExpiresIn > 0 {
+		lifetime = time.Duration(payload.ExpiresIn) * time.Second
+	}
+
+	return cachedToken{
+		value:     payload.AccessToken,
+		expiresAt: time.Now().Add(lifetime - tokenRefreshMargin),
+	}, nil
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"urlChecker/internal/domain/monitor"
+)
+
+// Prober performs a single check of one monitor type. It fills the
+// protocol-specific fields of result and returns an error when the target
+// should be considered down.
+type Prober interface {
+	Probe(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error
+}
+
+type ProberFunc func(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error
+
+func (f ProberFunc) Probe(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	return f(ctx, m, result)
+}
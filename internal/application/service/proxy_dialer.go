@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// dialTarget opens a TCP connection to address the way http checks of m
+// would reach it: through the monitor's proxy, the default proxy or the
+// proxy environment variables. rawURL is the http(s) equivalent of the
+// target and selects the environment proxy.
+func (s *CheckerService) dialTarget(ctx context.Context, m *monitor.URLMonitor, rawURL, address string) (net.Conn, error) {
+	proxy := s.proxyFor(m)
+	var proxyURL *url.URL
+	switch proxy {
+	case monitor.ProxyDirect:
+	case "":
+		target, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		proxyURL, err = http.ProxyFromEnvironment(&http.Request{URL: target})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		var err error
+		if proxyURL, err = url.Parse(proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	dialer := &net.Dialer{}
+	if proxyURL == nil {
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress(proxyURL))
+	if err != nil {
+		return nil, &net.OpError{Op: "proxyconnect", Net: "tcp", Err: err}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if proxyURL.Scheme == "socks5" {
+		err = socks5Connect(conn, proxyURL, address)
+	} else {
+		if proxyURL.Scheme == "https" {
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, &net.OpError{Op: "proxyconnect", Net: "tcp", Err: err}
+			}
+			conn = tlsConn
+		}
+		err = httpConnect(conn, proxyURL, address)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+var proxyDefaultPorts = map[string]string{"http": "80", "https": "443", "socks5": "1080"}
+
+func proxyAddress(proxyURL *url.URL) string {
+	if proxyURL.Port() != "" {
+		return proxyURL.Host
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), proxyDefaultPorts[proxyURL.Scheme])
+}
+
+// httpConnect opens a tunnel to address with an HTTP CONNECT request.
+func httpConnect(conn net.Conn, proxyURL *url.URL, address string) error {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+		req.Header.Set("Proxy-Authorization", req.Header.Get("Authorization"))
+		req.Header.Del("Authorization")
+	}
+	if err := req.Write(conn); err != nil {
+		return &net.OpError{Op: "proxyconnect", Net: "tcp", Err: err}
+	}
+
+	// The tunnel is silent until the client speaks, so nothing past the
+	// response is buffered.
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return &net.OpError{Op: "proxyconnect", Net: "tcp", Err: err}
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &proxyError{status: resp.Status}
+	}
+	return nil
+}
+
+// socks5Connect opens a tunnel to address through a SOCKS5 proxy (RFC
+// 1928), with username and password authentication (RFC 1929) when the
+// proxy URL carries credentials.
+func socks5Connect(conn net.Conn, proxyURL *url.URL, address string) error {
+	fail := func(err error) error {
+		return &net.OpError{Op: "socks connect", Net: "tcp", Err: err}
+	}
+
+	method := byte(0)
+	if proxyURL.User != nil {
+		method = 2
+	}
+	if _, err := conn.Write([]byte{5, 1, method}); err != nil {
+		return fail(err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fail(err)
+	}
+	if reply[0] != 5 || reply[1] != method {
+		return fail(fmt.Errorf("proxy refused authentication method %d", method))
+	}
+
+	if method == 2 {
+		username := proxyURL.User.Username()
+		password, _ := proxyURL.User.Password()
+		auth := append([]byte{1, byte(len(username))}, username...)
+		auth = append(append(auth, byte(len(password))), password...)
+		if _, err := conn.Write(auth); err != nil {
+			return fail(err)
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return fail(err)
+		}
+		if reply[1] != 0 {
+			return fail(fmt.Errorf("proxy rejected credentials"))
+		}
+	}
+
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return fail(err)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return fail(err)
+	}
+	request := []byte{5, 1, 0}
+	if ip := net.ParseIP(host).To4(); ip != nil {
+		request = append(append(request, 1), ip...)
+	} else if ip := net.ParseIP(host); ip != nil {
+		request = append(append(request, 4), ip...)
+	} else {
+		request = append(append(request, 3, byte(len(host))), host...)
+	}
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+	if _, err := conn.Write(request); err != nil {
+		return fail(err)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fail(err)
+	}
+	if header[1] != 0 {
+		return fail(fmt.Errorf("proxy refused connection with code %d", header[1]))
+	}
+	var skip int
+	switch header[3] {
+	case 1:
+		skip = net.IPv4len
+	case 4:
+		skip = net.IPv6len
+	case 3:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fail(err)
+		}
+		skip = int(length[0])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, skip+2)); err != nil {
+		return fail(err)
+	}
+	return nil
+}
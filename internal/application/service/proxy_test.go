@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+// newForwardProxy отвечает на запросы вместо цели и отклоняет CONNECT
+func newForwardProxy(hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if r.Method == http.MethodConnect {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("via proxy " + r.URL.Host))
+	}))
+}
+
+// newSOCKS5Proxy — минимальный SOCKS5 без аутентификации, только CONNECT по IPv4
+func newSOCKS5Proxy(t *testing.T, hits *int32) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				atomic.AddInt32(hits, 1)
+
+				greeting := make([]byte, 2)
+				io.ReadFull(conn, greeting)
+				io.ReadFull(conn, make([]byte, greeting[1]))
+				conn.Write([]byte{5, 0})
+
+				request := make([]byte, 10)
+				if _, err := io.ReadFull(conn, request); err != nil || request[3] != 1 {
+					return
+				}
+				ip := net.IP(request[4:8])
+				port := binary.BigEndian.Uint16(request[8:10])
+				target, err := net.Dial("tcp", net.JoinHostPort(ip.String(), strconv.Itoa(int(port))))
+				if err != nil {
+					conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer target.Close()
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+				go io.Copy(target, conn)
+				io.Copy(conn, target)
+			}()
+		}
+	}()
+	return ln
+}
+
+func TestHTTPProber_HTTPProxy(t *testing.T) {
+	var hits int32
+	proxy := newForwardProxy(&hits)
+	defer proxy.Close()
+
+	m := monitor.NewURLMonitor("http://target.invalid/health", time.Minute)
+	m.SetProxy(proxy.URL)
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertContains, Value: "via proxy target.invalid"}})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up through proxy, got %s (%s)", result.Status, result.Error)
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("expected 1 request through proxy, got %d", hits)
+	}
+}
+
+func TestHTTPProber_SOCKS5Proxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	var hits int32
+	proxy := newSOCKS5Proxy(t, &hits)
+	defer proxy.Close()
+
+	m := monitor.NewURLMonitor(target.URL, time.Minute)
+	m.SetProxy("socks5://" + proxy.Addr().String())
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up through socks5, got %s (%s)", result.Status, result.Error)
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("expected 1 socks5 connection, got %d", hits)
+	}
+}
+
+func TestHTTPProber_DefaultProxyAndBypass(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	}))
+	defer target.Close()
+
+	var hits int32
+	proxy := newForwardProxy(&hits)
+	defer proxy.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	if err := checker.SetDefaultProxy(proxy.URL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	proxied := monitor.NewURLMonitor(target.URL, time.Minute)
+	direct := monitor.NewURLMonitor(target.URL, time.Minute)
+	direct.SetProxy(monitor.ProxyDirect)
+	direct.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertContains, Value: "direct"}})
+	repo.Save(proxied)
+	repo.Save(direct)
+
+	checker.checkURL(context.Background(), proxied)
+	checker.checkURL(context.Background(), direct)
+
+	// Через прокси должен пройти только монитор без собственной настройки
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("expected only the default-proxy monitor to use the proxy, got %d hits", hits)
+	}
+	if direct.LastStatus != monitor.StatusUp {
+		t.Errorf("expected direct monitor to bypass the proxy, got %s", direct.LastStatus)
+	}
+}
+
+func TestHTTPProber_ClassifiesProxyFailures(t *testing.T) {
+	var hits int32
+	proxy := newForwardProxy(&hits)
+	defer proxy.Close()
+
+	cases := []struct {
+		name   string
+		target string
+		proxy  string
+		want   monitor.ErrorKind
+	}{
+		{"proxy unreachable", "http://target.invalid", "http://127.0.0.1:1", monitor.ErrorKindProxy},
+		{"socks5 unreachable", "http://target.invalid", "socks5://127.0.0.1:1", monitor.ErrorKindProxy},
+		{"connect refused", "https://target.invalid", proxy.URL, monitor.ErrorKindProxy},
+		{"target unreachable", "http://127.0.0.1:1", monitor.ProxyDirect, monitor.ErrorKindTarget},
+	}
+	for _, c := range cases {
+		m := monitor.NewURLMonitor(c.target, time.Minute)
+		m.SetProxy(c.proxy)
+
+		result := checkOnce(t, m)
+
+		if result.Status != monitor.StatusDown || result.ErrorKind != c.want {
+			t.Errorf("%s: expected down with %s error, got %s / %q (%s)", c.name, c.want, result.Status, result.ErrorKind, result.Error)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// schedule orders monitors by their next run. Changes wake the loop
+// waiting on it, so a new or edited monitor does not wait for the
+// previously earliest run.
+type schedule struct {
+	mu      sync.Mutex
+	entries map[string]*scheduleEntry
+	queue   scheduleQueue
+	wake    chan struct{}
+}
+
+type scheduleEntry struct {
+	id    string
+	next  time.Time
+	index int
+}
+
+func newSchedule() *schedule {
+	return &schedule{
+		entries: make(map[string]*scheduleEntry),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// set schedules the monitor's next run, replacing an earlier one.
+func (s *schedule) set(id string, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[id]; ok {
+		entry.next = next
+		heap.Fix(&s.queue, entry.index)
+	} else {
+		entry := &scheduleEntry{id: id, next: next}
+		s.entries[id] = entry
+		heap.Push(&s.queue, entry)
+	}
+	s.notify()
+}
+
+func (s *schedule) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[id]; ok {
+		heap.Remove(&s.queue, entry.index)
+		delete(s.entries, id)
+		s.notify()
+	}
+}
+
+// popDue removes and returns the monitors due at now with the time each
+// was due; the caller schedules their next run.
+func (s *schedule) popDue(now time.Time) map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make(map[string]time.Time)
+	for len(s.queue) > 0 && !s.queue[0].next.After(now) {
+		entry := heap.Pop(&s.queue).(*scheduleEntry)
+		delete(s.entries, entry.id)
+		due[entry.id] = entry.next
+	}
+	return due
+}
+
+// next returns the earliest scheduled run, or false when nothing is
+// scheduled.
+func (s *schedule) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+	return s.queue[0].next, true
+}
+
+func (s *schedule) has(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[id]
+	return ok
+}
+
+func (s *schedule) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+func (s *schedule) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// scheduleQueue is a min-heap of entries by next run.
+type scheduleQueue []*scheduleEntry
+
+func (q scheduleQueue) Len() int           { return len(q) }
+func (q scheduleQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduleQueue) Push(x any) {
+	entry := x.(*scheduleEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *scheduleQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return entry
+}
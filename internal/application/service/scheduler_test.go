@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+func TestSchedule_OrdersByNextRun(t *testing.T) {
+	s := newSchedule()
+	base := time.Now()
+
+	s.set("c", base.Add(3*time.Second))
+	s.set("a", base.Add(1*time.Second))
+	s.set("b", base.Add(2*time.Second))
+	// Перенос уже запланированного монитора
+	s.set("c", base.Add(500*time.Millisecond))
+	s.remove("b")
+
+	if next, ok := s.next(); !ok || !next.Equal(base.Add(500*time.Millisecond)) {
+		t.Errorf("expected earliest run to be c, got %v", next)
+	}
+
+	due := s.popDue(base.Add(1500 * time.Millisecond))
+	if len(due) != 2 || !due["a"].Equal(base.Add(time.Second)) || !due["c"].Equal(base.Add(500*time.Millisecond)) {
+		t.Errorf("expected a and c to be due, got %v", due)
+	}
+	if s.len() != 0 {
+		t.Errorf("expected an empty schedule, got %d entries", s.len())
+	}
+	if _, ok := s.next(); ok {
+		t.Error("expected no next run")
+	}
+}
+
+func TestCheckerService_RunDueKeepsCadence(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	defer checker.pool.stop()
+
+	m := monitor.NewURLMonitor("https://example.invalid", 10*time.Minute)
+	repo.Save(m)
+	due := time.Now().Add(-2 * time.Second)
+	checker.schedule.set(m.ID, due)
+
+	checker.runDue(time.Now())
+
+	// Следующий запуск отсчитывается от запланированного, а не от фактического времени
+	if next, _ := checker.schedule.next(); !next.Equal(due.Add(10 * time.Minute)) {
+		t.Errorf("expected next run one interval after the due time, got %v", next.Sub(due))
+	}
+}
+
+func TestCheckerService_SchedulesCron(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	defer checker.pool.stop()
+
+	m := monitor.NewURLMonitor("https://example.invalid", time.Minute)
+	m.SetCron("0 3 * * *", "UTC")
+	repo.Save(m)
+
+	checker.MonitorSaved(m)
+
+	// Интервал игнорируется, следующий запуск — ближайшие 03:00 UTC
+	next, _ := checker.schedule.next()
+	if next.UTC().Hour() != 3 || next.Minute() != 0 || !next.After(time.Now()) || next.Sub(time.Now()) > 24*time.Hour {
+		t.Errorf("expected next run at 03:00 UTC, got %v", next)
+	}
+
+	checker.schedule.set(m.ID, time.Now().Add(-time.Second))
+	checker.runDue(time.Now())
+	if after, _ := checker.schedule.next(); !after.Equal(next) {
+		t.Errorf("expected run after a due cron run at %v, got %v", next, after)
+	}
+}
+
+func TestCheckerService_Jitter(t *testing.T) {
+	checker := NewCheckerService(nil, nil, &MockLogger{})
+	m := monitor.NewURLMonitor("https://example.com", 10*time.Second)
+
+	if err := checker.SetJitter(-time.Second); err == nil {
+		t.Error("expected error for negative jitter")
+	}
+	checker.SetJitter(time.Hour)
+
+	for range 100 {
+		if jitter := checker.jitterWithin(m.Interval); jitter < 0 || jitter >= 5*time.Second {
+			t.Fatalf("expected jitter within half the interval, got %v", jitter)
+		}
+	}
+}
+
+func TestMonitorService_NotifiesScheduler(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	service := NewMonitorService(repo, repo)
+	service.SetListener(checker)
+
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
+	if checker.schedule.len() != 1 {
+		t.Fatalf("expected created monitor to be scheduled")
+	}
+
+	service.PauseMonitor(m.ID)
+	if checker.schedule.len() != 0 {
+		t.Error("expected paused monitor to be unscheduled")
+	}
+
+	service.ResumeMonitor(m.ID)
+	service.DeleteMonitor(m.ID)
+	if checker.schedule.len() != 0 {
+		t.Error("expected deleted monitor to be unscheduled")
+	}
+}
+
+func TestCheckerService_Start_ChecksNewMonitorImmediately(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+	service := NewMonitorService(repo, repo)
+	service.SetListener(checker)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		service.CreateMonitor(MonitorParams{URL: server.URL, Interval: time.Hour})
+	}()
+	runFor(checker, 300*time.Millisecond)
+
+	if mockLogger.count() != 1 {
+		t.Errorf("expected monitor created while running to be checked once, got %d", mockLogger.count())
+	}
+}
+
+// failingRepo не может прочитать монитор, как при недоступной базе
+type failingRepo struct {
+	*repository.MemoryRepository
+}
+
+func (r failingRepo) FindByID(id string) (*monitor.URLMonitor, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestCheckerService_RunDueKeepsMonitorOnLoadError(t *testing.T) {
+	repo := failingRepo{repository.NewMemoryRepository()}
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	defer checker.pool.stop()
+
+	now := time.Now()
+	checker.schedule.set("broken", now.Add(-time.Second))
+	checker.runDue(now)
+
+	// Временная ошибка не снимает монитор с расписания
+	if next, ok := checker.schedule.next(); !ok || !next.Equal(now.Add(loadRetryDelay)) {
+		t.Errorf("expected a retry after %v, got %v", loadRetryDelay, next)
+	}
+
+	memory := repository.NewMemoryRepository()
+	checker = NewCheckerService(memory, memory, &MockLogger{})
+	defer checker.pool.stop()
+	checker.schedule.set("deleted", now.Add(-time.Second))
+	checker.runDue(now)
+	if checker.schedule.has("deleted") {
+		t.Error("expected a deleted monitor to be dropped")
+	}
+}
+
+// flakyRepo не может прочитать список мониторов при первой попытке
+type flakyRepo struct {
+	*repository.MemoryRepository
+	calls atomic.Int32
+}
+
+func (r *flakyRepo) FindAll() ([]*monitor.URLMonitor, error) {
+	if r.calls.Add(1) == 1 {
+		return nil, errors.New("database is locked")
+	}
+	return r.MemoryRepository.FindAll()
+}
+
+func TestCheckerService_Start_RetriesInitialLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := &flakyRepo{MemoryRepository: repository.NewMemoryRepository()}
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+	repo.Save(monitor.NewURLMonitor(server.URL, time.Minute))
+
+	// Первая загрузка падает, повтор через секунду находит монитор
+	runFor(checker, 1500*time.Millisecond)
+
+	if calls := repo.calls.Load(); calls != 2 {
+		t.Errorf("expected the initial load to be retried once, got %d loads", calls)
+	}
+	if mockLogger.count() != 1 {
+		t.Errorf("expected the monitor to be checked after the retry, got %d checks", mockLogger.count())
+	}
+}
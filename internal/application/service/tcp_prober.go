@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+type tcpProber struct {
+	dialer *net.Dialer
+}
+
+func newTCPProber() *tcpProber {
+	return &tcpProber{dialer: &net.Dialer{}}
+}
+
+func (p *tcpProber) Probe(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	address, err := monitor.TCPAddress(m.URL)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	conn, err := p.dialer.DialContext(ctx, "tcp", address)
+	connected := time.Now()
+	result.ResponseTime = connected.Sub(start)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.Body == "" && len(m.Assertions) == 0 {
+		return nil
+	}
+	result.Timings = &monitor.Timings{TCPConnect: connected.Sub(start)}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if m.Body != "" {
+		if _, err := io.WriteString(conn, m.Body); err != nil {
+			return fmt.Errorf("sending payload: %w", err)
+		}
+	}
+
+	return readUntilMatch(conn, m, result, connected)
+}
+
+// readUntilMatch reads a stream response until the monitor's assertions
+// pass, the peer closes the connection or the deadline expires. Reading
+// stops early because banners and replies rarely end with EOF.
+func readUntilMatch(conn net.Conn, m *monitor.URLMonitor, result *monitor.CheckResult, sent time.Time) error {
+	var response []byte
+	buf := make([]byte, 4096)
+
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if len(response) == 0 {
+				result.Timings.TimeToFirstByte = time.Since(sent)
+			}
+			response = append(response, buf[:n]...)
+			if failed, _ := m.CheckBody(response); failed == nil {
+				result.ResponseTime += time.Since(sent)
+				return nil
+			}
+		}
+
+		if err != nil || len(response) >= maxBodySize {
+			result.ResponseTime += time.Since(sent)
+			if len(response) == 0 {
+				return fmt.Errorf("no response: %w", err)
+			}
+			if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrDeadlineExceeded) {
+				return fmt.Errorf("reading response: %w", err)
+			}
+			return verifyBody(m, response, result)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+func TestCheckerService_CheckURL_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+
+	m := monitor.NewURLMonitor("tcp://"+listener.Addr().String(), 1*time.Minute)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stored result, got %d", len(results))
+	}
+	if results[0].Status != monitor.StatusUp {
+		t.Errorf("expected status up, got %s (%s)", results[0].Status, results[0].Error)
+	}
+	if results[0].ResponseTime <= 0 {
+		t.Error("expected connect latency to be recorded")
+	}
+}
+
+func TestCheckerService_CheckURL_TCPRefused(t *testing.T) {
+	// Занимаем порт и сразу освобождаем, чтобы соединение было отклонено
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	repo := repository.NewMemoryRepository()
+	mockLogger := &MockLogger{}
+	checker := NewCheckerService(repo, repo, mockLogger)
+
+	m := monitor.NewURLMonitor("tcp://"+address, 1*time.Minute)
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stored result, got %d", len(results))
+	}
+	if results[0].Status != monitor.StatusDown || results[0].Error == "" {
+		t.Errorf("expected down with failure reason, got %+v", results[0])
+	}
+}
+
+// serveTCP принимает одно соединение и передаёт его в handle
+func serveTCP(t *testing.T, handle func(conn net.Conn)) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}()
+	return listener
+}
+
+func TestTCPProber_Banner(t *testing.T) {
+	listener := serveTCP(t, func(conn net.Conn) {
+		// Баннер приходит частями, соединение остаётся открытым
+		conn.Write([]byte("220 mail.example.com "))
+		time.Sleep(20 * time.Millisecond)
+		conn.Write([]byte("ESMTP ready\r\n"))
+		conn.Read(make([]byte, 1))
+	})
+	defer listener.Close()
+
+	m := monitor.NewURLMonitor("tcp://"+listener.Addr().String(), time.Minute)
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertRegex, Value: `^220 .*ESMTP`}})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+	}
+	if result.Timings == nil || result.Timings.TimeToFirstByte <= 0 {
+		t.Errorf("expected time to first byte, got %+v", result.Timings)
+	}
+}
+
+func TestTCPProber_PayloadResponse(t *testing.T) {
+	listener := serveTCP(t, func(conn net.Conn) {
+		buf := make([]byte, 64)
+		n, _ := conn.Read(buf)
+		if string(buf[:n]) == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		} else {
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+	})
+	defer listener.Close()
+
+	m := monitor.NewURLMonitor("tcp://"+listener.Addr().String(), time.Minute)
+	m.SetRequest("GET", nil, "PING\r\n")
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertContains, Value: "+PONG"}})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestTCPProber_ResponseMismatch(t *testing.T) {
+	listener := serveTCP(t, func(conn net.Conn) {
+		conn.Write([]byte("554 no service\r\n"))
+	})
+	defer listener.Close()
+
+	m := monitor.NewURLMonitor("tcp://"+listener.Addr().String(), time.Minute)
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertRegex, Value: `^220`}})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown || result.FailedAssertion == "" {
+		t.Errorf("expected down with failed assertion, got %s (%q)", result.Status, result.FailedAssertion)
+	}
+}
+
+func TestUDPProber_PayloadResponse(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, 64)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		conn.WriteTo(append([]byte("pong:"), buf[:n]...), addr)
+	}()
+
+	m := monitor.NewURLMonitor("udp://"+conn.LocalAddr().String(), time.Minute)
+	m.SetRequest("GET", nil, "\xffping")
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertContains, Value: "pong:\xffping"}})
+
+	result := checkOnce(t, m)
+
+	if m.Type != monitor.TypeUDP {
+		t.Errorf("expected type udp, got %s", m.Type)
+	}
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestUDPProber_NoReply(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	m := monitor.NewURLMonitor("udp://"+conn.LocalAddr().String(), time.Minute)
+	m.SetRequest("GET", nil, "ping")
+	m.SetTimeouts(100*time.Millisecond, 0)
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown {
+		t.Errorf("expected down without reply, got %s", result.Status)
+	}
+
+	// Без полезной нагрузки UDP-проверка не имеет смысла
+	m.SetRequest("GET", nil, "")
+	if result := checkOnce(t, m); result.Status != monitor.StatusDown || result.Error == "" {
+		t.Errorf("expected down without payload, got %s", result.Status)
+	}
+}
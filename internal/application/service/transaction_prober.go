@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// probeTransaction runs the monitor's steps in order, feeding extracted
+// variables forward, and stops at the first step that fails.
+func (s *CheckerService) probeTransaction(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	vars := map[string]string{}
+
+	for i, step := range m.Steps {
+		name := step.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+
+		stepResult := monitor.StepResult{Name: name}
+		err := s.runStep(ctx, m, step, vars, &stepResult, result)
+		result.Steps = append(result.Steps, stepResult)
+		result.ResponseTime += stepResult.ResponseTime
+		result.StatusCode = stepResult.StatusCode
+
+		if err != nil {
+			if stepResult.FailedAssertion != "" {
+				result.FailedAssertion = fmt.Sprintf("step %s: %s", name, stepResult.FailedAssertion)
+			}
+			return fmt.Errorf("step %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (s *CheckerService) runStep(ctx context.Context, m *monitor.URLMonitor, step monitor.TransactionStep, vars map[string]string, stepResult *monitor.StepResult, result *monitor.CheckResult) error {
+	expanded, err := step.Expand(m.URL, vars)
+	if err != nil {
+		stepResult.Error = err.Error()
+		return err
+	}
+	stepResult.URL = expanded.URL
+
+	// Each step runs as an http monitor that inherits the transaction's
+	// auth, TLS, proxy and redirect settings.
+	stepMonitor := *m
+	stepMonitor.Type = monitor.TypeHTTP
+	stepMonitor.URL = expanded.URL
+	stepMonitor.Method = expanded.Method
+	stepMonitor.Headers = expanded.Headers
+	stepMonitor.Body = expanded.Body
+	stepMonitor.ExpectedStatus = expanded.ExpectedStatus
+	stepMonitor.Assertions = expanded.Assertions
+	stepMonitor.ExpectedURL = ""
+
+	scratch := monitor.NewCheckResult(m.ID, time.Now())
+	resp, body, err := s.exchange(ctx, &stepMonitor, scratch)
+	stepResult.StatusCode = scratch.StatusCode
+	stepResult.ResponseTime = scratch.ResponseTime
+	stepResult.FailedAssertion = scratch.FailedAssertion
+	if scratch.ErrorKind != "" {
+		result.ErrorKind = scratch.ErrorKind
+	}
+	if err != nil {
+		stepResult.Error = err.Error()
+		return err
+	}
+
+	for _, e := range step.Extract {
+		value, err := e.Extract(resp.Header, body)
+		if err != nil {
+			err = fmt.Errorf("extracting %s: %w", e.Variable, err)
+			stepResult.Error = err.Error()
+			return err
+		}
+		vars[e.Variable] = value
+	}
+	return nil
+}
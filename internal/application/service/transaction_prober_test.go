@@ -0,0 +1,108 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// newShopServer — логин выдаёт токен, заказы доступны только с ним
+func newShopServer(orders string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Session", "s-1")
+		json.NewEncoder(w).Encode(map[string]string{"token": "t-1"})
+	})
+	mux.HandleFunc("GET /orders", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t-1" || r.Header.Get("X-Session") != "s-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(orders))
+	})
+	return httptest.NewServer(mux)
+}
+
+func newTransactionMonitor(t *testing.T, base string) *monitor.URLMonitor {
+	m := monitor.NewURLMonitor(base, time.Minute)
+	m.SetType(monitor.TypeTransaction)
+	err := m.SetSteps([]monitor.TransactionStep{
+		{
+			Name:   "login",
+			Method: "POST",
+			URL:    "/login",
+			Body:   `{"user":"probe"}`,
+			Extract: []monitor.Extraction{
+				{Variable: "token", Source: monitor.ExtractJSONPath, Expression: "$.token"},
+				{Variable: "session", Source: monitor.ExtractHeader, Expression: "X-Session"},
+			},
+		},
+		{
+			Name:       "orders",
+			URL:        "/orders",
+			Headers:    map[string]string{"Authorization": "Bearer {{token}}", "X-Session": "{{session}}"},
+			Assertions: []monitor.BodyAssertion{{Type: monitor.AssertJSONPath, Path: "$.orders", Operator: monitor.OpGreater, Value: "0"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected valid steps, got %v", err)
+	}
+	return m
+}
+
+func TestTransactionProber_RunsStepsWithVariables(t *testing.T) {
+	server := newShopServer(`{"orders":[{"id":1}]}`)
+	defer server.Close()
+
+	result := checkOnce(t, newTransactionMonitor(t, server.URL))
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+	}
+	if len(result.Steps) != 2 {
+		t.Fatalf("expected 2 step results, got %d", len(result.Steps))
+	}
+	if result.Steps[1].URL != server.URL+"/orders" || result.Steps[1].StatusCode != 200 {
+		t.Errorf("unexpected orders step %+v", result.Steps[1])
+	}
+}
+
+func TestTransactionProber_StopsAtFailingStep(t *testing.T) {
+	server := newShopServer(`{"orders":[]}`)
+	defer server.Close()
+
+	result := checkOnce(t, newTransactionMonitor(t, server.URL))
+
+	if result.Status != monitor.StatusDown {
+		t.Errorf("expected down for empty orders, got %s", result.Status)
+	}
+	if !strings.HasPrefix(result.FailedAssertion, "step orders:") {
+		t.Errorf("expected failed assertion to name the step, got %q", result.FailedAssertion)
+	}
+	if len(result.Steps) != 2 || result.Steps[0].Error != "" || result.Steps[1].Error == "" {
+		t.Errorf("expected only the orders step to fail, got %+v", result.Steps)
+	}
+}
+
+func TestTransactionProber_ExtractionFailure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"locked"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	result := checkOnce(t, newTransactionMonitor(t, server.URL))
+
+	// Второй шаг не должен выполняться без токена
+	if result.Status != monitor.StatusDown || len(result.Steps) != 1 {
+		t.Errorf("expected down after the login step, got %s with %d steps", result.Status, len(result.Steps))
+	}
+	if !strings.Contains(result.Error, "extracting token") {
+		t.Errorf("expected extraction error, got %q", result.Error)
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// maxTransports bounds the transport pool; the least recently used
+// profile is dropped beyond it.
+const maxTransports = 64
+
+// transportKey identifies a transport profile. Proxy is the effective proxy
+// URL, ProxyDirect, or empty to honour the proxy environment variables.
+type transportKey struct {
+	tls   monitor.TLSConfig
+	proxy string
+}
+
+// transportPool keeps one client per transport profile so connections are
+// reused across checks of monitors with the same profile. A client is
+// rebuilt when one of its certificate files changes, so certificates
+// rotated in place are picked up.
+type transportPool struct {
+	mu      sync.Mutex
+	clients map[transportKey]*pooledClient
+}
+
+type pooledClient struct {
+	client   *http.Client
+	modTimes []time.Time
+	lastUsed time.Time
+}
+
+func newTransportPool() *transportPool {
+	return &transportPool{clients: make(map[transportKey]*pooledClient)}
+}
+
+// SetDefaultProxy routes http checks of monitors without their own proxy
+// through proxy. An empty proxy restores the environment defaults.
+func (s *CheckerService) SetDefaultProxy(proxy string) error {
+	if err := monitor.ValidateProxy(proxy); err != nil {
+		return err
+	}
+	s.defaultProxy = proxy
+	return nil
+}
+
+// clientFor returns the client to probe m with. Monitors without a TLS
+// profile or proxy use the checker's shared client.
+func (s *CheckerService) clientFor(m *monitor.URLMonitor) (*http.Client, error) {
+	key := transportKey{proxy: s.proxyFor(m)}
+	if m.TLS != nil {
+		key.tls = *m.TLS
+	}
+
+	if key == (transportKey{}) {
+		return s.client, nil
+	}
+	return s.transports.client(key)
+}
+
+// proxyFor returns the monitor's effective proxy: its own, else the
+// default one, else empty for the environment.
+func (s *CheckerService) proxyFor(m *monitor.URLMonitor) string {
+	if m.Proxy != "" {
+		return m.Proxy
+	}
+	return s.defaultProxy
+}
+
+func (p *transportPool) client(key transportKey) (*http.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	modTimes := tlsFileModTimes(key.tls)
+	if pooled, ok := p.clients[key]; ok {
+		if slices.EqualFunc(pooled.modTimes, modTimes, time.Time.Equal) {
+			pooled.lastUsed = time.Now()
+			return pooled.client, nil
+		}
+		pooled.client.CloseIdleConnections()
+		delete(p.clients, key)
+	}
+
+	client, err := newTransportClient(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.clients) >= maxTransports {
+		p.evictOldest()
+	}
+	p.clients[key] = &pooledClient{client: client, modTimes: modTimes, lastUsed: time.Now()}
+	return client, nil
+}
+
+func (p *transportPool) evictOldest() {
+	var oldest transportKey
+	var oldestUsed time.Time
+	for key, pooled := range p.clients {
+		if oldestUsed.IsZero() || pooled.lastUsed.Before(oldestUsed) {
+			oldest, oldestUsed = key, pooled.lastUsed
+		}
+	}
+	p.clients[oldest].client.CloseIdleConnections()
+	delete(p.clients, oldest)
+}
+
+// tlsFileModTimes returns the modification times of the profile's files;
+// a missing file reads as the zero time.
+func tlsFileModTimes(profile monitor.TLSConfig) []time.Time {
+	var modTimes []time.Time
+	for _, path := range []string{profile.CertFile, profile.KeyFile, profile.CAFile} {
+		if path == "" {
+			continue
+		}
+		var modTime time.Time
+		if info, err := os.Stat(path); err == nil {
+			modTime = info.ModTime()
+		}
+		modTimes = append(modTimes, modTime)
+	}
+	return modTimes
+}
+
+func newTransportClient(key transportKey) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(key.tls)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	switch key.proxy {
+	case "":
+	case monitor.ProxyDirect:
+		transport.Proxy = nil
+	default:
+		proxyURL, err := url.Parse(key.proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+		transport.OnProxyConnectResponse = rejectProxyConnect
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func buildTLSConfig(profile monitor.TLSConfig) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         profile.ServerName,
+		InsecureSkipVerify: profile.InsecureSkipVerify,
+	}
+
+	if profile.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(profile.CertFile, profile.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if profile.CAFile != "" {
+		pem, err := os.ReadFile(profile.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", profile.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// proxyError is a CONNECT request refused by the proxy.
+type proxyError struct {
+	status string
+}
+
+func (e *proxyError) Error() string {
+	return "proxy refused CONNECT: " + e.status
+}
+
+func rejectProxyConnect(_ context.Context, _ *url.URL, _ *http.Request, resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return &proxyError{status: resp.Status}
+	}
+	return nil
+}
+
+// isProxyError reports whether err happened while connecting to or through
+// the proxy rather than talking to the target.
+func isProxyError(err error) bool {
+	var refused *proxyError
+	if errors.As(err, &refused) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks"))
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// testPKI — приватный CA с сертификатами сервера и клиента, записанными в PEM-файлы
+type testPKI struct {
+	caFile, certFile, keyFile string
+	caPool                    *x509.CertPool
+	server                    tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	dir := t.TempDir()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage, names []string) ([]byte, *ecdsa.PrivateKey) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "test"},
+			DNSNames:     names,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().AddDate(1, 0, 0),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der, key
+	}
+
+	pki := &testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client-key.pem"),
+		caPool:   x509.NewCertPool(),
+	}
+	pki.caPool.AddCert(caCert)
+
+	serverDER, serverKey := issue(2, x509.ExtKeyUsageServerAuth, []string{"checker.internal"})
+	pki.server = tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+
+	clientDER, clientKey := issue(3, x509.ExtKeyUsageClientAuth, nil)
+	keyDER, _ := x509.MarshalECPrivateKey(clientKey)
+
+	writePEM(t, pki.caFile, "CERTIFICATE", caDER)
+	writePEM(t, pki.certFile, "CERTIFICATE", clientDER)
+	writePEM(t, pki.keyFile, "EC PRIVATE KEY", keyDER)
+	return pki
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newMTLSServer требует клиентский сертификат, подписанный тестовым CA
+func newMTLSServer(pki *testPKI) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.caPool,
+	}
+	server.StartTLS()
+	return server
+}
+
+func TestHTTPProber_MutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newMTLSServer(pki)
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(server.URL, time.Minute)
+	m.SetTLSConfig(&monitor.TLSConfig{
+		CertFile:   pki.certFile,
+		KeyFile:    pki.keyFile,
+		CAFile:     pki.caFile,
+		ServerName: "checker.internal",
+	})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up with client certificate, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestHTTPProber_MutualTLSWithoutClientCert(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newMTLSServer(pki)
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(server.URL, time.Minute)
+	m.SetTLSConfig(&monitor.TLSConfig{CAFile: pki.caFile, ServerName: "checker.internal"})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown {
+		t.Errorf("expected down without client certificate, got %s", result.Status)
+	}
+}
+
+func TestHTTPProber_TLSVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Самоподписанный сертификат httptest не проходит проверку по системным корням
+	m := monitor.NewURLMonitor(server.URL, time.Minute)
+	m.SetTLSConfig(&monitor.TLSConfig{ServerName: "example.com"})
+	if result := checkOnce(t, m); result.Status != monitor.StatusDown {
+		t.Errorf("expected down for untrusted certificate, got %s", result.Status)
+	}
+
+	m = monitor.NewURLMonitor(server.URL, time.Minute)
+	m.SetTLSConfig(&monitor.TLSConfig{InsecureSkipVerify: true})
+	if result := checkOnce(t, m); result.Status != monitor.StatusUp {
+		t.Errorf("expected up with skip verify, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestHTTPProber_MissingCABundle(t *testing.T) {
+	m := monitor.NewURLMonitor("https://127.0.0.1:1", time.Minute)
+	m.SetTLSConfig(&monitor.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown || result.Error == "" {
+		t.Errorf("expected down with CA bundle error, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestTransportPool_SharesClientPerProfile(t *testing.T) {
+	pool := newTransportPool()
+
+	a, _ := pool.client(transportKey{tls: monitor.TLSConfig{ServerName: "a.internal"}})
+	b, _ := pool.client(transportKey{tls: monitor.TLSConfig{ServerName: "a.internal"}})
+	c, _ := pool.client(transportKey{tls: monitor.TLSConfig{ServerName: "b.internal"}})
+
+	if a != b {
+		t.Error("expected equal profiles to share a client")
+	}
+	if a == c {
+		t.Error("expected different profiles to get separate clients")
+	}
+}
+
+func TestTransportPool_ReloadsRotatedCertificates(t *testing.T) {
+	pki := newTestPKI(t)
+	pool := newTransportPool()
+	key := transportKey{tls: monitor.TLSConfig{CertFile: pki.certFile, KeyFile: pki.keyFile, CAFile: pki.caFile}}
+
+	before, err := pool.client(key)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if again, _ := pool.client(key); again != before {
+		t.Error("expected an unchanged profile to reuse its client")
+	}
+
+	// Сертификат перевыпущен по тому же пути
+	rotated := time.Now().Add(time.Minute)
+	os.Chtimes(pki.certFile, rotated, rotated)
+	if after, _ := pool.client(key); after == before {
+		t.Error("expected a rotated certificate to rebuild the client")
+	}
+}
+
+func TestTransportPool_EvictsLeastRecentlyUsed(t *testing.T) {
+	pool := newTransportPool()
+
+	first := transportKey{tls: monitor.TLSConfig{ServerName: "first.internal"}}
+	pool.client(first)
+	for i := range maxTransports {
+		pool.client(transportKey{tls: monitor.TLSConfig{ServerName: fmt.Sprintf("%d.internal", i)}})
+	}
+
+	if len(pool.clients) != maxTransports {
+		t.Errorf("expected at most %d clients, got %d", maxTransports, len(pool.clients))
+	}
+	if _, ok := pool.clients[first]; ok {
+		t.Error("expected the least recently used client to be evicted")
+	}
+}
+
+func TestHTTPProber_OAuth2TokenWithTLSProfile(t *testing.T) {
+	pki := newTestPKI(t)
+	tokenServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token-1", "expires_in": 3600})
+	}))
+	tokenServer.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.caPool,
+	}
+	tokenServer.StartTLS()
+	defer tokenServer.Close()
+	server := newMTLSServer(pki)
+	defer server.Close()
+
+	// Token endpoint доступен только с клиентским сертификатом и приватным CA
+	m := monitor.NewURLMonitor(server.URL, time.Minute)
+	m.SetTLSConfig(&monitor.TLSConfig{
+		CertFile:   pki.certFile,
+		KeyFile:    pki.keyFile,
+		CAFile:     pki.caFile,
+		ServerName: "checker.internal",
+	})
+	m.SetAuth(&monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: tokenServer.URL, ClientID: "checker", ClientSecret: "s3cret"})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up with a token fetched over mTLS, got %s (%s)", result.Status, result.Error)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+const maxDatagramSize = 65535
+
+type udpProber struct {
+	dialer *net.Dialer
+}
+
+func newUDPProber() *udpProber {
+	return &udpProber{dialer: &net.Dialer{}}
+}
+
+// Probe sends the payload as one datagram and checks the first datagram
+// received. Without a reply UDP cannot tell a live service from a dropped
+// packet, so a payload is required.
+func (p *udpProber) Probe(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	address, err := monitor.UDPAddress(m.URL)
+	if err != nil {
+		return err
+	}
+	if m.Body == "" {
+		return errors.New("udp check needs a payload to send")
+	}
+
+	conn, err := p.dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	sent := time.Now()
+	if _, err := conn.Write([]byte(m.Body)); err != nil {
+		return fmt.Errorf("sending payload: %w", err)
+	}
+
+	buf := make([]byte, maxDatagramSize)
+	n, err := conn.Read(buf)
+	result.ResponseTime = time.Since(sent)
+	result.Timings = &monitor.Timings{TimeToFirstByte: result.ResponseTime}
+	if err != nil {
+		return fmt.Errorf("no response: %w", err)
+	}
+
+	return verifyBody(m, buf[:n], result)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+func TestCheckerService_WatchStoresSnapshotsOnChange(t *testing.T) {
+	page := "<main>Service operational</main><footer>rendered at 12:00</footer>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	m := monitor.NewURLMonitor(server.URL, time.Minute)
+	m.SetWatch(&monitor.Watch{Select: `<main>(.*)</main>`})
+	repo.Save(m)
+
+	checker.checkURL(context.Background(), m)
+	// Футер меняется, но он вне селектора
+	page = "<main>Service operational</main><footer>rendered at 12:01</footer>"
+	checker.checkURL(context.Background(), m)
+	page = "<main>Hacked by someone</main>"
+	checker.checkURL(context.Background(), m)
+
+	snapshots, _ := repo.FindSnapshots(m.ID)
+	if len(snapshots) != 2 {
+		t.Fatalf("expected baseline and one change, got %d snapshots", len(snapshots))
+	}
+	if snapshots[1].Content != "Hacked by someone" {
+		t.Errorf("unexpected snapshot content %q", snapshots[1].Content)
+	}
+
+	results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+	changed := []bool{results[0].ContentChanged, results[1].ContentChanged, results[2].ContentChanged}
+	if changed[0] || changed[1] || !changed[2] {
+		t.Errorf("expected only the last check to be a change, got %v", changed)
+	}
+
+	service := NewMonitorService(repo, repo)
+	diff, err := service.DiffSnapshots(m.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(diff, "-Service operational\n+Hacked by someone\n") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
+
+func TestCheckerService_WatchSelectorMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(server.URL, time.Minute)
+	m.SetWatch(&monitor.Watch{Select: "$.version"})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown || !strings.Contains(result.Error, "watch") {
+		t.Errorf("expected down with watch error, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestMonitorService_DiffSnapshotsNeedsTwo(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	service := NewMonitorService(repo, repo)
+	m, _ := service.CreateMonitor(MonitorParams{URL: "https://example.com", Interval: 5 * time.Minute})
+	repo.SaveSnapshot(&monitor.Snapshot{MonitorID: m.ID, Content: "only"})
+
+	if _, err := service.DiffSnapshots(m.ID, 0, 0); err == nil {
+		t.Error("expected error with a single snapshot")
+	}
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// websocketGUID is appended to the client key to compute the accept key
+// (RFC 6455, section 4.2.2).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+func (s *CheckerService) probeWebSocket(ctx context.Context, m *monitor.URLMonitor, result *monitor.CheckResult) error {
+	target, err := url.Parse(m.URL)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	conn, err := s.dialWebSocket(ctx, m, target)
+	if err != nil {
+		result.ResponseTime = time.Since(start)
+		if isProxyError(err) {
+			result.ErrorKind = monitor.ErrorKindProxy
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// The context deadline bounds the handshake and the wait for a reply.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	reader := bufio.NewReader(conn)
+	statusCode, err := s.upgrade(ctx, m, target, conn, reader)
+	result.StatusCode = statusCode
+	handshakeDone := time.Now()
+	result.Timings = &monitor.Timings{Handshake: handshakeDone.Sub(start)}
+	if err != nil {
+		result.ResponseTime = handshakeDone.Sub(start)
+		return err
+	}
+
+	if m.Body == "" && len(m.Assertions) == 0 {
+		result.ResponseTime = handshakeDone.Sub(start)
+		closeWebSocket(conn)
+		return nil
+	}
+
+	sent := time.Now()
+	if m.Body != "" {
+		if err := writeFrame(conn, opText, []byte(m.Body)); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
+	}
+	reply, err := readMessage(conn, reader)
+	end := time.Now()
+	result.ResponseTime = end.Sub(start)
+	result.Timings.RoundTrip = end.Sub(sent)
+	if err != nil {
+		return fmt.Errorf("waiting for reply: %w", err)
+	}
+	closeWebSocket(conn)
+
+	return verifyBody(m, reply, result)
+}
+
+func (s *CheckerService) dialWebSocket(ctx context.Context, m *monitor.URLMonitor, target *url.URL) (net.Conn, error) {
+	port := target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	address := net.JoinHostPort(target.Hostname(), port)
+
+	// Proxies are chosen by the http(s) URL the handshake is sent to.
+	httpURL := *target
+	httpURL.Scheme = strings.Replace(target.Scheme, "ws", "http", 1)
+	conn, err := s.dialTarget(ctx, m, httpURL.String(), address)
+	if err != nil || target.Scheme != "wss" {
+		return conn, err
+	}
+
+	var profile monitor.TLSConfig
+	if m.TLS != nil {
+		profile = *m.TLS
+	}
+	config, err := buildTLSConfig(profile)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if config.ServerName == "" {
+		config.ServerName = target.Hostname()
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if state := tlsConn.ConnectionState(); len(state.PeerCertificates) > 0 {
+		m.Certificate = monitor.NewCertificateInfo(state.PeerCertificates[0])
+	}
+	return tlsConn, nil
+}
+
+// upgrade sends the opening handshake and validates the server's answer.
+func (s *CheckerService) upgrade(ctx context.Context, m *monitor.URLMonitor, target *url.URL, conn net.Conn, reader *bufio.Reader) (int, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	handshakeURL := *target
+	handshakeURL.Scheme = "http"
+	if target.Scheme == "wss" {
+		handshakeURL.Scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, handshakeURL.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range m.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if err := s.applyAuth(ctx, m, req); err != nil {
+		return 0, err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(conn); err != nil {
+		return 0, err
+	}
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return resp.StatusCode, fmt.Errorf("handshake returned status %d, expected 101", resp.StatusCode)
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return resp.StatusCode, errors.New("handshake response lacks Upgrade: websocket")
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return resp.StatusCode, errors.New("handshake returned an invalid Sec-WebSocket-Accept")
+	}
+	return resp.StatusCode, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// writeFrame sends a single masked frame, as clients must.
+func writeFrame(w io.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, 0x80|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 0x80|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 0x80|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	header = append(header, mask...)
+
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+	_, err := w.Write(append(header, masked...))
+	return err
+}
+
+// readMessage returns the payload of the first data message, answering
+// pings and joining fragments on the way.
+func readMessage(conn net.Conn, reader *bufio.Reader) ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := readFrame(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := writeFrame(conn, opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return nil, errors.New("server closed the connection")
+		case opText, opBinary, opContinuation:
+			message = append(message, payload...)
+			if len(message) > maxBodySize {
+				return nil, fmt.Errorf("message exceeds %d bytes", maxBodySize)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected opcode %#x", opcode)
+		}
+
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func readFrame(reader *bufio.Reader) (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(reader, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(reader, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(reader, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxBodySize {
+		err = fmt.Errorf("frame of %d bytes exceeds %d", length, maxBodySize)
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(reader, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(reader, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// closeWebSocket sends a normal closure; the reply is not awaited.
+func closeWebSocket(conn net.Conn) {
+	writeFrame(conn, opClose, []byte{0x03, 0xE8})
+}
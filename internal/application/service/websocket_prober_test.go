@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+	"urlChecker/internal/infrastructure/repository"
+)
+
+// newWebSocketServer принимает upgrade и передаёт соединение в handle
+func newWebSocketServer(t *testing.T, handle func(rw *bufio.ReadWriter)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+		rw.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+
+		handle(rw)
+	}))
+}
+
+// serverFrame пишет немаскированный кадр, как это делает сервер
+func serverFrame(w *bufio.ReadWriter, opcode byte, payload string) {
+	w.Write([]byte{0x80 | opcode, byte(len(payload))})
+	w.WriteString(payload)
+	w.Flush()
+}
+
+func echo(rw *bufio.ReadWriter) {
+	_, _, payload, err := readFrame(rw.Reader)
+	if err != nil {
+		return
+	}
+	// Сначала ping, чтобы проверить, что клиент его пропускает
+	serverFrame(rw, opPing, "")
+	serverFrame(rw, opText, "echo: "+string(payload))
+	io.Copy(io.Discard, rw)
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/live"
+}
+
+func TestWebSocketProber_SendsAndAssertsReply(t *testing.T) {
+	server := newWebSocketServer(t, echo)
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(wsURL(server), time.Minute)
+	m.SetRequest("GET", nil, "ping")
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertContains, Value: "echo: ping"}})
+
+	result := checkOnce(t, m)
+
+	if m.Type != monitor.TypeWebSocket {
+		t.Errorf("expected type websocket, got %s", m.Type)
+	}
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+	}
+	if result.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("expected status 101, got %d", result.StatusCode)
+	}
+	if result.Timings == nil || result.Timings.Handshake <= 0 || result.Timings.RoundTrip <= 0 {
+		t.Errorf("expected handshake and round-trip latency, got %+v", result.Timings)
+	}
+}
+
+func TestWebSocketProber_AssertionFails(t *testing.T) {
+	server := newWebSocketServer(t, echo)
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(wsURL(server), time.Minute)
+	m.SetRequest("GET", nil, "ping")
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertContains, Value: "pong"}})
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown || result.FailedAssertion == "" {
+		t.Errorf("expected down with failed assertion, got %s (%q)", result.Status, result.FailedAssertion)
+	}
+}
+
+func TestWebSocketProber_HandshakeOnly(t *testing.T) {
+	server := newWebSocketServer(t, func(rw *bufio.ReadWriter) {
+		io.Copy(io.Discard, rw)
+	})
+	defer server.Close()
+
+	result := checkOnce(t, monitor.NewURLMonitor(wsURL(server), time.Minute))
+
+	if result.Status != monitor.StatusUp {
+		t.Errorf("expected up, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestWebSocketProber_RejectsPlainHTTP(t *testing.T) {
+	// Сервер отвечает обычным 200 без upgrade
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain http"))
+	}))
+	defer server.Close()
+
+	result := checkOnce(t, monitor.NewURLMonitor(wsURL(server), time.Minute))
+
+	if result.Status != monitor.StatusDown || result.StatusCode != http.StatusOK {
+		t.Errorf("expected down with status 200, got %s / %d", result.Status, result.StatusCode)
+	}
+}
+
+func TestWebSocketProber_ReplyTimeout(t *testing.T) {
+	server := newWebSocketServer(t, func(rw *bufio.ReadWriter) {
+		io.Copy(io.Discard, rw)
+	})
+	defer server.Close()
+
+	m := monitor.NewURLMonitor(wsURL(server), time.Minute)
+	m.SetRequest("GET", nil, "ping")
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertContains, Value: "pong"}})
+	m.SetTimeouts(200*time.Millisecond, 0)
+
+	result := checkOnce(t, m)
+
+	if result.Status != monitor.StatusDown || !strings.Contains(result.Error, "waiting for reply") {
+		t.Errorf("expected down waiting for reply, got %s (%s)", result.Status, result.Error)
+	}
+}
+
+func TestWriteFrame_RoundTrip(t *testing.T) {
+	var buf strings.Builder
+	payload := strings.Repeat("x", 300)
+
+	if err := writeFrame(&buf, opText, []byte(payload)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	raw := buf.String()
+	if raw[1]&0x80 == 0 || raw[1]&0x7F != 126 || binary.BigEndian.Uint16([]byte(raw[2:4])) != 300 {
+		t.Fatalf("expected a masked frame with 16-bit length, got header % x", raw[:4])
+	}
+	fin, opcode, got, err := readFrame(bufio.NewReader(strings.NewReader(raw)))
+	if err != nil || !fin || opcode != opText || string(got) != payload {
+		t.Errorf("expected payload to round-trip, got fin=%v opcode=%d err=%v", fin, opcode, err)
+	}
+}
+
+// newConnectProxy туннелирует CONNECT к цели и считает туннели
+func newConnectProxy(t *testing.T, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		atomic.AddInt32(hits, 1)
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer target.Close()
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 Connection established\r\n\r\n")
+		rw.Flush()
+
+		go io.Copy(target, rw)
+		io.Copy(conn, target)
+	}))
+}
+
+func TestWebSocketProber_Proxies(t *testing.T) {
+	server := newWebSocketServer(t, echo)
+	defer server.Close()
+
+	var connectHits, socksHits, refusedHits int32
+	connectProxy := newConnectProxy(t, &connectHits)
+	defer connectProxy.Close()
+	socksProxy := newSOCKS5Proxy(t, &socksHits)
+	defer socksProxy.Close()
+	refusingProxy := newForwardProxy(&refusedHits)
+	defer refusingProxy.Close()
+
+	repo := repository.NewMemoryRepository()
+	checker := NewCheckerService(repo, repo, &MockLogger{})
+	checker.SetDefaultProxy(connectProxy.URL)
+
+	cases := []struct {
+		name  string
+		proxy string
+		hits  *int32
+		want  monitor.Status
+		kind  monitor.ErrorKind
+	}{
+		{"default proxy", "", &connectHits, monitor.StatusUp, ""},
+		{"socks5", "socks5://" + socksProxy.Addr().String(), &socksHits, monitor.StatusUp, ""},
+		{"refused", refusingProxy.URL, &refusedHits, monitor.StatusDown, monitor.ErrorKindProxy},
+	}
+	for _, c := range cases {
+		m := monitor.NewURLMonitor(wsURL(server), time.Minute)
+		m.SetRequest("GET", nil, "ping")
+		m.SetProxy(c.proxy)
+		repo.Save(m)
+
+		checker.checkURL(context.Background(), m)
+
+		results, _ := repo.FindResults(m.ID, time.Time{}, time.Time{})
+		if len(results) != 1 || results[0].Status != c.want || results[0].ErrorKind != c.kind {
+			t.Errorf("%s: expected %s %q, got %+v", c.name, c.want, c.kind, results)
+		}
+		if atomic.LoadInt32(c.hits) != 1 {
+			t.Errorf("%s: expected the handshake to go through the proxy, got %d connections", c.name, atomic.LoadInt32(c.hits))
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"urlChecker/internal/domain/monitor"
+)
+
+const (
+	DefaultWorkers      = 16
+	DefaultPerHostLimit = 4
+	DefaultQueueSize    = 1000
+)
+
+// PoolStats is a point-in-time view of the check queue.
+type PoolStats struct {
+	Workers  int `json:"workers"`
+	Queued   int `json:"queued"`
+	Running  int `json:"running"`
+	Rejected int `json:"rejected"`
+}
+
+// checkPool runs checks on a fixed number of workers. A monitor is queued
+// at most once and never runs concurrently with itself, and at most
+// perHost checks of the same host run at a time. Workers skip over queued
+// checks whose host is busy, so one slow host does not hold up the rest.
+type checkPool struct {
+	workers   int
+	perHost   int
+	queueSize int
+	run       func(context.Context, *monitor.URLMonitor)
+	// ctx is passed to every check and cancelled by stop.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    []*monitor.URLMonitor
+	inFlight map[string]bool
+	hosts    map[string]int
+	running  int
+	rejected int
+	started  bool
+	stopped  bool
+	wg       sync.WaitGroup
+}
+
+func newCheckPool(run func(context.Context, *monitor.URLMonitor)) *checkPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &checkPool{
+		ctx:       ctx,
+		cancel:    cancel,
+		workers:   DefaultWorkers,
+		perHost:   DefaultPerHostLimit,
+		queueSize: DefaultQueueSize,
+		run:       run,
+		inFlight:  make(map[string]bool),
+		hosts:     make(map[string]int),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// submit queues a check of m. It returns false when m is already queued or
+// running, or the queue is full; the monitor is then picked up again on a
+// later tick.
+func (p *checkPool) submit(m *monitor.URLMonitor) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped || p.inFlight[m.ID] {
+		return false
+	}
+	if len(p.queue) >= p.queueSize {
+		p.rejected++
+		return false
+	}
+	if !p.started {
+		p.started = true
+		p.wg.Add(p.workers)
+		for range p.workers {
+			go p.work()
+		}
+	}
+
+	p.inFlight[m.ID] = true
+	p.queue = append(p.queue, m)
+	p.cond.Signal()
+	return true
+}
+
+// stop discards the queue, cancels running checks and waits for them to
+// return.
+func (p *checkPool) stop() {
+	p.mu.Lock()
+	p.stopped = true
+	for _, m := range p.queue {
+		delete(p.inFlight, m.ID)
+	}
+	p.queue = nil
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.cancel()
+	p.wg.Wait()
+}
+
+func (p *checkPool) stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		Workers:  p.workers,
+		Queued:   len(p.queue),
+		Running:  p.running,
+		Rejected: p.rejected,
+	}
+}
+
+func (p *checkPool) work() {
+	defer p.wg.Done()
+	for {
+		m, host, ok := p.next()
+		if !ok {
+			return
+		}
+		p.run(p.ctx, m)
+		p.done(m, host)
+	}
+}
+
+// next blocks until a queued check can start within its host's limit.
+func (p *checkPool) next() (*monitor.URLMonitor, string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		if p.stopped {
+			return nil, "", false
+		}
+		for i, m := range p.queue {
+			host := checkHost(m)
+			if host != "" && p.hosts[host] >= p.perHost {
+				continue
+			}
+			p.queue = append(p.queue[:i], p.queue[i+1:]...)
+			p.hosts[host]++
+			p.running++
+			return m, host, true
+		}
+		p.cond.Wait()
+	}
+}
+
+func (p *checkPool) done(m *monitor.URLMonitor, host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inFlight, m.ID)
+	p.hosts[host]--
+	if p.hosts[host] == 0 {
+		delete(p.hosts, host)
+	}
+	p.running--
+	// A freed host slot may unblock any waiting worker.
+	p.cond.Broadcast()
+}
+
+// checkHost is the host a check connects to; heartbeat monitors have none
+// and are not limited.
+func checkHost(m *monitor.URLMonitor) string {
+	if m.Type == monitor.TypeHeartbeat {
+		return ""
+	}
+	target, err := url.Parse(m.URL)
+	if err != nil || target.Hostname() == "" {
+		return m.URL
+	}
+	return target.Hostname()
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+// blockingPool запускает проверки, которые ждут release
+func blockingPool(workers, perHost int) (*checkPool, chan string, chan struct{}) {
+	started := make(chan string, 100)
+	release := make(chan struct{})
+	pool := newCheckPool(func(_ context.Context, m *monitor.URLMonitor) {
+		started <- m.URL
+		<-release
+	})
+	pool.workers = workers
+	pool.perHost = perHost
+	return pool, started, release
+}
+
+func waitStarted(t *testing.T, started chan string, n int) []string {
+	t.Helper()
+	var urls []string
+	for range n {
+		select {
+		case url := <-started:
+			urls = append(urls, url)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d checks to start, got %d", n, len(urls))
+		}
+	}
+	return urls
+}
+
+func TestCheckPool_PerHostLimit(t *testing.T) {
+	pool, started, release := blockingPool(8, 2)
+	defer pool.stop()
+
+	for i := range 5 {
+		pool.submit(monitor.NewURLMonitor("https://busy.example/"+string(rune('a'+i)), time.Minute))
+	}
+	pool.submit(monitor.NewURLMonitor("https://other.example/", time.Minute))
+
+	// Две проверки занятого хоста и одна другого, остальные ждут в очереди
+	urls := waitStarted(t, started, 3)
+	select {
+	case url := <-started:
+		t.Fatalf("expected host limit to hold, but %s started", url)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	var other bool
+	for _, url := range urls {
+		other = other || url == "https://other.example/"
+	}
+	if !other {
+		t.Errorf("expected the other host not to wait behind the busy one, started %v", urls)
+	}
+	if stats := pool.stats(); stats.Queued != 3 || stats.Running != 3 {
+		t.Errorf("expected 3 queued and 3 running, got %+v", stats)
+	}
+
+	close(release)
+	waitStarted(t, started, 3)
+}
+
+func TestCheckPool_NeverRunsMonitorTwice(t *testing.T) {
+	pool, started, release := blockingPool(4, 4)
+	defer pool.stop()
+	m := monitor.NewURLMonitor("https://example.com", time.Minute)
+
+	if !pool.submit(m) {
+		t.Fatal("expected first submit to be accepted")
+	}
+	waitStarted(t, started, 1)
+	if pool.submit(m) {
+		t.Error("expected a running monitor not to be queued again")
+	}
+
+	release <- struct{}{}
+	// После завершения монитор снова можно поставить в очередь
+	deadline := time.Now().Add(time.Second)
+	for !pool.submit(m) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected monitor to be accepted after its check finished")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+}
+
+func TestCheckPool_QueueFull(t *testing.T) {
+	pool, started, release := blockingPool(1, 1)
+	defer pool.stop()
+	pool.queueSize = 1
+
+	pool.submit(monitor.NewURLMonitor("https://a.example", time.Minute))
+	waitStarted(t, started, 1)
+	pool.submit(monitor.NewURLMonitor("https://b.example", time.Minute))
+
+	if pool.submit(monitor.NewURLMonitor("https://c.example", time.Minute)) {
+		t.Error("expected submit to fail when the queue is full")
+	}
+	if stats := pool.stats(); stats.Queued != 1 || stats.Rejected != 1 || stats.Workers != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	close(release)
+}
+
+func TestCheckerService_SetConcurrency(t *testing.T) {
+	checker := NewCheckerService(nil, nil, &MockLogger{})
+
+	if err := checker.SetConcurrency(-1, 0, 0); err == nil {
+		t.Error("expected error for negative workers")
+	}
+	checker.SetConcurrency(2, 0, 10)
+
+	if checker.pool.workers != 2 || checker.pool.perHost != DefaultPerHostLimit || checker.pool.queueSize != 10 {
+		t.Errorf("unexpected pool limits %+v", checker.Stats())
+	}
+}
+
+func TestCheckPool_BoundedWorkers(t *testing.T) {
+	var mu sync.Mutex
+	var running, peak int
+	pool := newCheckPool(func(_ context.Context, m *monitor.URLMonitor) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	pool.workers = 3
+	defer pool.stop()
+
+	for i := range 12 {
+		pool.submit(monitor.NewURLMonitor("https://host"+string(rune('a'+i))+".example", time.Minute))
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak != 3 {
+		t.Errorf("expected at most 3 concurrent checks, peak was %d", peak)
+	}
+}
+
+func TestCheckPool_StopWaitsForRunningChecks(t *testing.T) {
+	pool, started, release := blockingPool(2, 2)
+	pool.submit(monitor.NewURLMonitor("https://a.example", time.Minute))
+	pool.submit(monitor.NewURLMonitor("https://b.example", time.Minute))
+	waitStarted(t, started, 2)
+
+	stopped := make(chan struct{})
+	go func() {
+		pool.stop()
+		close(stopped)
+	}()
+
+	// Пока проверки выполняются, stop не возвращается
+	select {
+	case <-stopped:
+		t.Fatal("expected stop to wait for running checks")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected stop to return once checks finished")
+	}
+}
@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type AssertionType string
+
+const (
+	AssertContains    AssertionType = "contains"
+	AssertNotContains AssertionType = "not_contains"
+	AssertRegex       AssertionType = "regex"
+	AssertJSONPath    AssertionType = "jsonpath"
+)
+
+// Operators compare the element selected by a jsonpath assertion with its
+// Value. Ordering operators compare numbers; arrays and objects compare by
+// their length, so "$.orders > 0" asserts a non-empty list.
+const (
+	OpEqual        = "=="
+	OpNotEqual     = "!="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+)
+
+// BodyAssertion checks the response body. Path and Operator are only used by
+// jsonpath assertions, where Value is compared with the selected element;
+// an empty Operator means equality.
+type BodyAssertion struct {
+	Type     AssertionType
+	Path     string
+	Operator string
+	Value    string
+}
+
+func (a BodyAssertion) Validate() error {
+	switch a.Type {
+	case AssertContains, AssertNotContains:
+		if a.Value == "" {
+			return fmt.Errorf("%w: %s assertion needs a value", ErrInvalidConfig, a.Type)
+		}
+	case AssertRegex:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("%w: invalid regex %q: %v", ErrInvalidConfig, a.Value, err)
+		}
+	case AssertJSONPath:
+		if _, err := parseJSONPath(a.Path); err != nil {
+			return err
+		}
+		switch a.Operator {
+		case "", OpEqual, OpNotEqual:
+		case OpGreater, OpGreaterEqual, OpLess, OpLessEqual:
+			if _, err := strconv.ParseFloat(a.Value, 64); err != nil {
+				return fmt.Errorf("%w: operator %s needs a numeric value, got %q", ErrInvalidConfig, a.Operator, a.Value)
+			}
+		default:
+			return fmt.Errorf("%w: unknown operator %q", ErrInvalidConfig, a.Operator)
+		}
+	default:
+		return fmt.Errorf("%w: unknown assertion type %q", ErrInvalidConfig, a.Type)
+	}
+	return nil
+}
+
+// Evaluate returns nil when the body satisfies the assertion and an error
+// describing the mismatch otherwise.
+func (a BodyAssertion) Evaluate(body []byte) error {
+	switch a.Type {
+	case AssertContains:
+		if !bytes.Contains(body, []byte(a.Value)) {
+			return fmt.Errorf("body does not contain %q", a.Value)
+		}
+	case AssertNotContains:
+		if bytes.Contains(body, []byte(a.Value)) {
+			return fmt.Errorf("body contains %q", a.Value)
+		}
+	case AssertRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return err
+		}
+		if !re.Match(body) {
+			return fmt.Errorf("body does not match %q", a.Value)
+		}
+	case AssertJSONPath:
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("body is not valid JSON: %v", err)
+		}
+		value, err := EvalJSONPath(doc, a.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", a.Path, err)
+		}
+		return a.compare(value)
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+	return nil
+}
+
+func (a BodyAssertion) compare(value any) error {
+	got := JSONValueString(value)
+	switch a.Operator {
+	case "", OpEqual:
+		if got != a.Value {
+			return fmt.Errorf("%s is %q, expected %q", a.Path, got, a.Value)
+		}
+		return nil
+	case OpNotEqual:
+		if got == a.Value {
+			return fmt.Errorf("%s is %q", a.Path, got)
+		}
+		return nil
+	}
+
+	actual, ok := jsonNumber(value)
+	if !ok {
+		return fmt.Errorf("%s is %q, not a number", a.Path, got)
+	}
+	want, err := strconv.ParseFloat(a.Value, 64)
+	if err != nil {
+		return err
+	}
+
+	var pass bool
+	switch a.Operator {
+	case OpGreater:
+		pass = actual > want
+	case OpGreaterEqual:
+		pass = actual >= want
+	case OpLess:
+		pass = actual < want
+	case OpLessEqual:
+		pass = actual <= want
+	default:
+		return fmt.Errorf("unknown operator %q", a.Operator)
+	}
+	if !pass {
+		return fmt.Errorf("%s is %s, expected %s %s", a.Path, strconv.FormatFloat(actual, 'f', -1, 64), a.Operator, a.Value)
+	}
+	return nil
+}
+
+// jsonNumber reads a decoded JSON value as a number, using the length of
+// arrays and objects.
+func jsonNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	case []any:
+		return float64(len(v)), true
+	case map[string]any:
+		return float64(len(v)), true
+	}
+	return 0, false
+}
+
+func (a BodyAssertion) String() string {
+	if a.Type == AssertJSONPath {
+		op := a.Operator
+		if op == "" {
+			op = OpEqual
+		}
+		return fmt.Sprintf("jsonpath %s %s %q", a.Path, op, a.Value)
+	}
+	return fmt.Sprintf("%s %q", a.Type, a.Value)
+}
+
+// JSONValueString renders a decoded JSON value the way users write it in
+// assertions: strings without quotes, everything else as compact JSON.
+func JSONValueString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(encoded))
+}
@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBodyAssertion_Evaluate(t *testing.T) {
+	body := []byte(`{"status":"ok","checks":[{"name":"db","latency":12}],"healthy":true}`)
+
+	cases := []struct {
+		name      string
+		assertion BodyAssertion
+		pass      bool
+	}{
+		{"contains", BodyAssertion{Type: AssertContains, Value: `"ok"`}, true},
+		{"contains missing", BodyAssertion{Type: AssertContains, Value: "degraded"}, false},
+		{"not contains", BodyAssertion{Type: AssertNotContains, Value: "degraded"}, true},
+		{"not contains present", BodyAssertion{Type: AssertNotContains, Value: "status"}, false},
+		{"regex", BodyAssertion{Type: AssertRegex, Value: `"latency":\d+`}, true},
+		{"regex mismatch", BodyAssertion{Type: AssertRegex, Value: `^<html`}, false},
+		{"jsonpath string", BodyAssertion{Type: AssertJSONPath, Path: "$.status", Value: "ok"}, true},
+		{"jsonpath number", BodyAssertion{Type: AssertJSONPath, Path: "$.checks[0].latency", Value: "12"}, true},
+		{"jsonpath bool", BodyAssertion{Type: AssertJSONPath, Path: "$['healthy']", Value: "true"}, true},
+		{"jsonpath mismatch", BodyAssertion{Type: AssertJSONPath, Path: "$.status", Value: "degraded"}, false},
+		{"jsonpath missing key", BodyAssertion{Type: AssertJSONPath, Path: "$.missing", Value: "x"}, false},
+		{"jsonpath not equal", BodyAssertion{Type: AssertJSONPath, Path: "$.status", Operator: OpNotEqual, Value: "degraded"}, true},
+		{"jsonpath greater", BodyAssertion{Type: AssertJSONPath, Path: "$.checks[0].latency", Operator: OpGreater, Value: "10"}, true},
+		{"jsonpath less fails", BodyAssertion{Type: AssertJSONPath, Path: "$.checks[0].latency", Operator: OpLess, Value: "10"}, false},
+		{"jsonpath array length", BodyAssertion{Type: AssertJSONPath, Path: "$.checks", Operator: OpGreater, Value: "0"}, true},
+		{"jsonpath bool not number", BodyAssertion{Type: AssertJSONPath, Path: "$.healthy", Operator: OpGreaterEqual, Value: "1"}, false},
+	}
+
+	for _, tc := range cases {
+		err := tc.assertion.Evaluate(body)
+		if tc.pass && err != nil {
+			t.Errorf("%s: expected pass, got %v", tc.name, err)
+		}
+		if !tc.pass && err == nil {
+			t.Errorf("%s: expected failure", tc.name)
+		}
+	}
+}
+
+func TestBodyAssertion_JSONPathOnNonJSON(t *testing.T) {
+	a := BodyAssertion{Type: AssertJSONPath, Path: "$.status", Value: "ok"}
+
+	if err := a.Evaluate([]byte("<html>error</html>")); err == nil {
+		t.Error("expected failure for non-JSON body")
+	}
+}
+
+func TestBodyAssertion_Validate(t *testing.T) {
+	invalid := []BodyAssertion{
+		{Type: "equals", Value: "x"},
+		{Type: AssertContains},
+		{Type: AssertRegex, Value: "("},
+		{Type: AssertJSONPath, Path: "status"},
+		{Type: AssertJSONPath, Path: "$.items[abc]"},
+		{Type: AssertJSONPath, Path: "$.count", Operator: ">", Value: "many"},
+		{Type: AssertJSONPath, Path: "$.count", Operator: "~", Value: "1"},
+	}
+
+	for _, a := range invalid {
+		if err := a.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for %+v, got %v", a, err)
+		}
+	}
+}
+
+func TestEvalJSONPath(t *testing.T) {
+	doc := map[string]any{
+		"items": []any{
+			map[string]any{"id": "a"},
+			map[string]any{"id": "b"},
+		},
+	}
+
+	value, err := EvalJSONPath(doc, "$.items[-1].id")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value != "b" {
+		t.Errorf("expected b, got %v", value)
+	}
+}
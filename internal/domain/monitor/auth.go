@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type AuthType string
+
+const (
+	AuthBasic  AuthType = "basic"
+	AuthBearer AuthType = "bearer"
+	AuthOAuth2 AuthType = "oauth2"
+)
+
+// AuthConfig holds credentials for http monitors. Which fields are used
+// depends on Type: Username/Password for basic, Token for bearer and the
+// client credentials grant fields for oauth2.
+type AuthConfig struct {
+	Type         AuthType
+	Username     string
+	Password     string
+	Token        string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+func (a *AuthConfig) Validate() error {
+	switch a.Type {
+	case AuthBasic:
+		if a.Username == "" {
+			return fmt.Errorf("%w: basic auth needs a username", ErrInvalidConfig)
+		}
+	case AuthBearer:
+		if a.Token == "" {
+			return fmt.Errorf("%w: bearer auth needs a token", ErrInvalidConfig)
+		}
+	case AuthOAuth2:
+		parsed, err := url.Parse(a.TokenURL)
+		if err != nil || !parsed.IsAbs() {
+			return fmt.Errorf("%w: oauth2 needs an absolute token URL", ErrInvalidConfig)
+		}
+		if a.ClientID == "" {
+			return fmt.Errorf("%w: oauth2 needs a client id", ErrInvalidConfig)
+		}
+	default:
+		return fmt.Errorf("%w: unknown auth type %q", ErrInvalidConfig, a.Type)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuthConfig_Validate(t *testing.T) {
+	valid := []*AuthConfig{
+		{Type: AuthBasic, Username: "admin", Password: "secret"},
+		{Type: AuthBearer, Token: "abc"},
+		{Type: AuthOAuth2, TokenURL: "https://auth.example.com/token", ClientID: "checker"},
+	}
+	for _, auth := range valid {
+		if err := auth.Validate(); err != nil {
+			t.Errorf("expected %s auth to be valid, got %v", auth.Type, err)
+		}
+	}
+
+	invalid := []*AuthConfig{
+		{Type: AuthBasic},
+		{Type: AuthBearer},
+		{Type: AuthOAuth2, TokenURL: "/token", ClientID: "checker"},
+		{Type: AuthOAuth2, TokenURL: "https://auth.example.com/token"},
+		{Type: "digest"},
+	}
+	for _, auth := range invalid {
+		if err := auth.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for %+v, got %v", auth, err)
+		}
+	}
+}
+
+func TestURLMonitor_SetAuth(t *testing.T) {
+	m := NewURLMonitor("https://example.com", 5*time.Minute)
+
+	if err := m.SetAuth(&AuthConfig{Type: AuthBearer}); err == nil {
+		t.Error("expected error for bearer auth without token")
+	}
+	if m.Auth != nil {
+		t.Errorf("expected invalid auth to be rejected, got %+v", m.Auth)
+	}
+
+	m.SetAuth(&AuthConfig{Type: AuthBearer, Token: "abc"})
+	if err := m.SetAuth(nil); err != nil || m.Auth != nil {
+		t.Errorf("expected nil to disable auth, got %+v, %v", m.Auth, err)
+	}
+}
@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"crypto/x509"
+	"math"
+	"time"
+)
+
+const DefaultCertExpiryDays = 14
+
+type CertificateInfo struct {
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+func NewCertificateInfo(cert *x509.Certificate) *CertificateInfo {
+	return &CertificateInfo{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		DNSNames:  cert.DNSNames,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+	}
+}
+
+// DaysUntilExpiry rounds down, so a certificate expiring later today
+// reports 0 and an expired one reports a negative number.
+func (c *CertificateInfo) DaysUntilExpiry(now time.Time) int {
+	return int(math.Floor(c.NotAfter.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertificateInfo_DaysUntilExpiry(t *testing.T) {
+	now := time.Now()
+	cert := &CertificateInfo{NotAfter: now.Add(10*24*time.Hour + time.Hour)}
+
+	if days := cert.DaysUntilExpiry(now); days != 10 {
+		t.Errorf("expected 10 days, got %d", days)
+	}
+
+	expired := &CertificateInfo{NotAfter: now.Add(-36 * time.Hour)}
+	if days := expired.DaysUntilExpiry(now); days != -2 {
+		t.Errorf("expected -2 days, got %d", days)
+	}
+}
+
+func TestURLMonitor_CertificateStatus(t *testing.T) {
+	now := time.Now()
+	m := NewURLMonitor("https://example.com", 5*time.Minute)
+
+	if status := m.CertificateStatus(now); status != StatusUp {
+		t.Errorf("expected up without certificate, got %s", status)
+	}
+
+	m.Certificate = &CertificateInfo{NotAfter: now.Add(90 * 24 * time.Hour)}
+	if status := m.CertificateStatus(now); status != StatusUp {
+		t.Errorf("expected up for fresh certificate, got %s", status)
+	}
+
+	m.Certificate = &CertificateInfo{NotAfter: now.Add(5 * 24 * time.Hour)}
+	if status := m.CertificateStatus(now); status != StatusWarning {
+		t.Errorf("expected warning below threshold, got %s", status)
+	}
+
+	m.SetCertExpiryDays(0)
+	if status := m.CertificateStatus(now); status != StatusUp {
+		t.Errorf("expected up with warnings disabled, got %s", status)
+	}
+
+	m.Certificate = &CertificateInfo{NotAfter: now.Add(-time.Hour)}
+	if status := m.CertificateStatus(now); status != StatusDown {
+		t.Errorf("expected down for expired certificate, got %s", status)
+	}
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"time"
+)
+
+// ErrorKind tells whether a failed check could not reach the proxy or the
+// target itself.
+type ErrorKind string
+
+const (
+	ErrorKindTarget ErrorKind = "target"
+	ErrorKindProxy  ErrorKind = "proxy"
+)
+
+// CheckResult is one check of a monitor. Attempts counts probes including
+// retries; AttemptErrors holds the errors of the attempts before the final one.
+// RedirectChain lists the URLs an http check was redirected to, in order.
+// ErrorKind is set only for failed checks. Steps holds the per-step outcome
+// of transaction checks and Log the body sent with a heartbeat ping.
+// ContentChanged marks watch checks that captured a new snapshot and
+// InMaintenance checks run during a maintenance window.
+type CheckResult struct {
+	ID              int64
+	MonitorID       string
+	CheckedAt       time.Time
+	Status          Status
+	StatusCode      int
+	ResponseTime    time.Duration
+	Timings         *Timings
+	Error           string
+	ErrorKind       ErrorKind
+	FailedAssertion string
+	Attempts        int
+	AttemptErrors   []string
+	RedirectChain   []string
+	Steps           []StepResult
+	Log             string
+	ContentChanged  bool
+	InMaintenance   bool
+}
+
+func NewCheckResult(monitorID string, checkedAt time.Time) *CheckResult {
+	return &CheckResult{
+		MonitorID: monitorID,
+		CheckedAt: checkedAt,
+		Attempts:  1,
+	}
+}
+
+// Timings splits an HTTP check into phases. Phases that did not happen,
+// such as DNS on a reused connection, stay zero. TimeToFirstByte is the wait
+// between sending the request and the first response byte. Websocket checks
+// fill Handshake, from dialing to the accepted upgrade, and RoundTrip, from
+// sending the message to the first reply.
+type Timings struct {
+	DNSLookup       time.Duration
+	TCPConnect      time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	ContentTransfer time.Duration
+	Handshake       time.Duration
+	RoundTrip       time.Duration
+}
@@ -0,0 +1,307 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cronCacheSize bounds the cache of parsed schedules, which spares
+// maintenance checks from parsing every window's recurrence and loading
+// its time zone on each check.
+const cronCacheSize = 1024
+
+var cronCache = struct {
+	sync.Mutex
+	specs map[CronSchedule]*cronSpec
+}{specs: make(map[CronSchedule]*cronSpec)}
+
+// cronSearchYears bounds the search for the next run; an expression that
+// matches nothing within it, such as February 30th, never runs.
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonths = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdays = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// CronSchedule runs a monitor at the times matched by a standard
+// five-field cron expression (minute hour day-of-month month day-of-week)
+// in TimeZone, an IANA name; empty means UTC. Fields accept *, lists,
+// ranges, steps and month or weekday names, and the @hourly family of
+// macros is supported. As in cron, a day matches either day field when
+// both are restricted. Around daylight saving changes, times skipped when
+// clocks go forward do not run that day, and times in the hour repeated
+// when clocks go back run once, at their first occurrence.
+type CronSchedule struct {
+	Expression string
+	TimeZone   string
+}
+
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+	location                      *time.Location
+}
+
+func (c CronSchedule) Validate() error {
+	spec, err := c.parse()
+	if err != nil {
+		return err
+	}
+	if spec.next(time.Now()).IsZero() {
+		return fmt.Errorf("%w: cron expression %q never runs", ErrInvalidConfig, c.Expression)
+	}
+	return nil
+}
+
+// Next returns the first run strictly after t, or the zero time when the
+// expression is invalid or never matches.
+func (c CronSchedule) Next(t time.Time) time.Time {
+	spec, err := c.parse()
+	if err != nil {
+		return time.Time{}
+	}
+	return spec.next(t)
+}
+
+// NextRuns returns up to n runs after t.
+func (c CronSchedule) NextRuns(t time.Time, n int) []time.Time {
+	spec, err := c.parse()
+	if err != nil {
+		return nil
+	}
+	var runs []time.Time
+	for range n {
+		t = spec.next(t)
+		if t.IsZero() {
+			break
+		}
+		runs = append(runs, t)
+	}
+	return runs
+}
+
+// parse returns the schedule's parsed form, from the cache when possible.
+func (c CronSchedule) parse() (*cronSpec, error) {
+	cronCache.Lock()
+	spec, ok := cronCache.specs[c]
+	cronCache.Unlock()
+	if ok {
+		return spec, nil
+	}
+
+	spec, err := c.parseSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	cronCache.Lock()
+	if len(cronCache.specs) >= cronCacheSize {
+		clear(cronCache.specs)
+	}
+	cronCache.specs[c] = spec
+	cronCache.Unlock()
+	return spec, nil
+}
+
+func (c CronSchedule) parseSpec() (*cronSpec, error) {
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidConfig, c.TimeZone)
+	}
+
+	expression := strings.TrimSpace(c.Expression)
+	if macro, ok := cronMacros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron expression %q must have 5 fields", ErrInvalidConfig, c.Expression)
+	}
+
+	spec := &cronSpec{location: location}
+	parsers := []struct {
+		bits     *uint64
+		min, max int
+		names    map[string]int
+	}{
+		{&spec.minute, 0, 59, nil},
+		{&spec.hour, 0, 23, nil},
+		{&spec.dom, 1, 31, nil},
+		{&spec.month, 1, 12, cronMonths},
+		{&spec.dow, 0, 7, cronWeekdays},
+	}
+	for i, p := range parsers {
+		bits, err := parseCronField(fields[i], p.min, p.max, p.names)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cron field %q: %v", ErrInvalidConfig, fields[i], err)
+		}
+		*p.bits = bits
+	}
+
+	// 7 is an alias for Sunday.
+	if spec.dow&(1<<7) != 0 {
+		spec.dow |= 1
+	}
+	spec.domAny = strings.HasPrefix(fields[2], "*")
+	spec.dowAny = strings.HasPrefix(fields[4], "*")
+	return spec, nil
+}
+
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*":
+			low, high = min, max
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = cronValue(from, names); err != nil {
+				return 0, err
+			}
+			if high, err = cronValue(to, names); err != nil {
+				return 0, err
+			}
+		default:
+			value, err := cronValue(rangePart, names)
+			if err != nil {
+				return 0, err
+			}
+			low, high = value, value
+			// "5/15" means from 5 to the end in steps of 15.
+			if hasStep {
+				high = max
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%d-%d is outside %d-%d", low, high, min, max)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, names map[string]int) (int, error) {
+	if value, ok := names[strings.ToUpper(s)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return value, nil
+}
+
+func (s *cronSpec) next(after time.Time) time.Time {
+	t := after.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<int(t.Month())) == 0 {
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location))
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location))
+			continue
+		}
+		if s.hour&(1<<t.Hour()) == 0 {
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location))
+			continue
+		}
+		if s.minute&(1<<t.Minute()) == 0 || repeatedWallClock(t) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// forward moves the search from t to next, the start of a later month, day
+// or hour. Where clocks go forward at midnight that start does not exist
+// and next falls before t; the search then resumes at the following hour.
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	later := t.Add(time.Hour)
+	return time.Date(later.Year(), later.Month(), later.Day(), later.Hour(), 0, 0, 0, later.Location())
+}
+
+// repeatedWallClock reports whether t's local time already occurred
+// earlier, in the hour repeated when clocks go back.
+func repeatedWallClock(t time.Time) bool {
+	for _, shift := range []time.Duration{30 * time.Minute, time.Hour} {
+		earlier := t.Add(-shift)
+		if earlier.Day() == t.Day() && earlier.Hour() == t.Hour() && earlier.Minute() == t.Minute() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *cronSpec) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// SetCron schedules the monitor by a cron expression instead of its
+// interval, or clears the schedule when expression is empty. Heartbeat
+// monitors are driven by their pings and cannot use one.
+func (u *URLMonitor) SetCron(expression, timeZone string) error {
+	if expression == "" {
+		if timeZone != "" {
+			return fmt.Errorf("%w: time zone needs a cron expression", ErrInvalidConfig)
+		}
+		u.Cron = nil
+		return nil
+	}
+	if u.Type == TypeHeartbeat {
+		return fmt.Errorf("%w: heartbeat monitors cannot use a cron schedule", ErrInvalidConfig)
+	}
+
+	cron := CronSchedule{Expression: expression, TimeZone: timeZone}
+	if err := cron.Validate(); err != nil {
+		return err
+	}
+	u.Cron = &cron
+	u.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	kyiv, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		cron  CronSchedule
+		after time.Time
+		want  time.Time
+	}{
+		{
+			"every 5 minutes",
+			CronSchedule{Expression: "*/5 * * * *"},
+			time.Date(2024, 5, 1, 10, 2, 30, 0, time.UTC),
+			time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC),
+		},
+		{
+			"strictly after",
+			CronSchedule{Expression: "*/5 * * * *"},
+			time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC),
+			time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC),
+		},
+		{
+			// Пятница вечером — следующий запуск в понедельник утром по Киеву
+			"business hours in time zone",
+			CronSchedule{Expression: "*/5 9-17 * * MON-FRI", TimeZone: "Europe/Kyiv"},
+			time.Date(2024, 5, 3, 18, 0, 0, 0, kyiv),
+			time.Date(2024, 5, 6, 9, 0, 0, 0, kyiv),
+		},
+		{
+			"macro",
+			CronSchedule{Expression: "@daily"},
+			time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC),
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"month names and offset step",
+			CronSchedule{Expression: "10/20 3 1 jan,jul *"},
+			time.Date(2024, 1, 1, 3, 15, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC),
+		},
+		{
+			// Оба поля дня ограничены — подходит любое из них
+			"day of month or weekday",
+			CronSchedule{Expression: "0 0 13 * 5"},
+			time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 9, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"sunday as 7",
+			CronSchedule{Expression: "0 12 * * 7"},
+			time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 5, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cron.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_Validate(t *testing.T) {
+	invalid := []CronSchedule{
+		{Expression: "* * * *"},
+		{Expression: "60 * * * *"},
+		{Expression: "*/0 * * * *"},
+		{Expression: "5-1 * * * *"},
+		{Expression: "* * * * FUNDAY"},
+		{Expression: "0 0 30 2 *"},
+		{Expression: "* * * * *", TimeZone: "Mars/Olympus"},
+	}
+	for _, cron := range invalid {
+		if err := cron.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for %+v, got %v", cron, err)
+		}
+	}
+}
+
+func TestCronSchedule_NextRuns(t *testing.T) {
+	cron := CronSchedule{Expression: "0 */6 * * *"}
+
+	runs := cron.NextRuns(time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC), 3)
+
+	if len(runs) != 3 || runs[0].Hour() != 6 || runs[1].Hour() != 12 || runs[2].Hour() != 18 {
+		t.Errorf("unexpected runs %v", runs)
+	}
+}
+
+func TestURLMonitor_SetCron(t *testing.T) {
+	m := NewURLMonitor("https://example.com", 0)
+
+	if err := m.SetCron("*/5 * * * *", "UTC"); err != nil || m.Cron == nil {
+		t.Fatalf("expected cron to be set, got %v", err)
+	}
+	if err := m.SetCron("", "UTC"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for time zone without expression, got %v", err)
+	}
+	if err := m.SetCron("", ""); err != nil || m.Cron != nil {
+		t.Errorf("expected cron to be cleared, got %v", err)
+	}
+
+	m.SetType(TypeHeartbeat)
+	if err := m.SetCron("@hourly", ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for heartbeat monitor, got %v", err)
+	}
+}
+
+func TestCronSchedule_DaylightSaving(t *testing.T) {
+	// Переход на летнее время в полночь: полуночи не существует
+	cases := []struct {
+		zone string
+		from time.Time
+	}{
+		{"America/Santiago", time.Date(2026, 9, 5, 12, 30, 0, 0, time.UTC)},
+		{"America/Havana", time.Date(2026, 3, 7, 20, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		done := make(chan []time.Time, 1)
+		go func() {
+			done <- CronSchedule{Expression: "0 12 * * *", TimeZone: c.zone}.NextRuns(c.from, 4)
+		}()
+
+		select {
+		case runs := <-done:
+			if len(runs) != 4 {
+				t.Fatalf("%s: expected 4 runs, got %v", c.zone, runs)
+			}
+			for i, run := range runs {
+				if run.Hour() != 12 || run.Minute() != 0 || (i > 0 && run.Sub(runs[i-1]) < 23*time.Hour) {
+					t.Errorf("%s: expected daily runs at 12:00, got %v", c.zone, runs)
+				}
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: next run search did not finish", c.zone)
+		}
+	}
+
+	// Повторяющийся час при переходе на зимнее время — запуск только один раз
+	runs := CronSchedule{Expression: "30 1 * * *", TimeZone: "America/New_York"}.NextRuns(time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC), 2)
+	want := []time.Time{time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC)}
+	if len(runs) != 2 || !runs[0].Equal(want[0]) || !runs[1].Equal(want[1]) {
+		t.Errorf("expected one run in the repeated hour, got %v", runs)
+	}
+}
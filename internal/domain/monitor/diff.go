@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// maxDiffCells bounds the LCS table; larger changes are shown as a full
+// replacement of the differing region.
+const maxDiffCells = 4 << 20
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff renders the line changes from one snapshot to another in
+// unified diff format. It returns an empty string when the contents match.
+func UnifiedDiff(from, to *Snapshot) string {
+	ops := diffLines(splitLines(from.Content), splitLines(to.Content))
+
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- snapshot %d\t%s\n", from.ID, from.CapturedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "+++ snapshot %d\t%s\n", to.ID, to.CapturedAt.Format(time.RFC3339))
+
+	for i := 0; i < len(changes); {
+		// Changes separated by less than two contexts share a hunk.
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*diffContext {
+			j++
+		}
+		start := max(changes[i]-diffContext, 0)
+		end := min(changes[j]+diffContext+1, len(ops))
+		writeHunk(&b, ops, start, end)
+		i = j + 1
+	}
+	return b.String()
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp, start, end int) {
+	var oldLine, newLine int
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+
+	var oldCount, newCount int
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	// An empty range names the line before it, as diff -u does.
+	if oldCount > 0 {
+		oldLine++
+	}
+	if newCount > 0 {
+		newLine++
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	for _, op := range ops[start:end] {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+		b.WriteByte('\n')
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a shortest edit script between a and b using a
+// longest common subsequence over the region between their common prefix
+// and suffix.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+func diffMiddle(a, b []string) []diffOp {
+	var ops []diffOp
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
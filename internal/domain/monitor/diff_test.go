@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func snapshot(id int64, content string) *Snapshot {
+	return &Snapshot{ID: id, CapturedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Content: content}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	from := snapshot(1, "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n")
+	to := snapshot(2, "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n")
+
+	got := UnifiedDiff(from, to)
+
+	want := strings.Join([]string{
+		"--- snapshot 1\t2024-05-01T12:00:00Z",
+		"+++ snapshot 2\t2024-05-01T12:00:00Z",
+		"@@ -1,5 +1,5 @@",
+		" a",
+		"-b",
+		"+B",
+		" c",
+		" d",
+		" e",
+		"@@ -10,3 +10,4 @@",
+		" j",
+		" k",
+		" l",
+		"+m",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_MergesNearbyChanges(t *testing.T) {
+	got := UnifiedDiff(snapshot(1, "a\nb\nc\nd\n"), snapshot(2, "x\nb\nc\ny\n"))
+
+	if strings.Count(got, "@@ -") != 1 || !strings.Contains(got, "@@ -1,4 +1,4 @@") {
+		t.Errorf("expected a single hunk, got:\n%s", got)
+	}
+}
+
+func TestUnifiedDiff_EmptySides(t *testing.T) {
+	if got := UnifiedDiff(snapshot(1, "same"), snapshot(2, "same")); got != "" {
+		t.Errorf("expected no diff for equal content, got %q", got)
+	}
+
+	got := UnifiedDiff(snapshot(1, ""), snapshot(2, "new\n"))
+	if !strings.Contains(got, "@@ -0,0 +1,1 @@\n+new\n") {
+		t.Errorf("expected an insertion into empty content, got:\n%s", got)
+	}
+}
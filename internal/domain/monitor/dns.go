@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+type DNSRecordType string
+
+const (
+	DNSRecordA     DNSRecordType = "A"
+	DNSRecordAAAA  DNSRecordType = "AAAA"
+	DNSRecordCNAME DNSRecordType = "CNAME"
+	DNSRecordMX    DNSRecordType = "MX"
+	DNSRecordTXT   DNSRecordType = "TXT"
+)
+
+// DNSCheck configures a dns monitor. An empty Server uses the system
+// resolver; Expected values must all appear in the answer.
+type DNSCheck struct {
+	Server   string
+	Record   DNSRecordType
+	Expected []string
+}
+
+func (c *DNSCheck) Validate() error {
+	switch c.Record {
+	case DNSRecordA, DNSRecordAAAA, DNSRecordCNAME, DNSRecordMX, DNSRecordTXT:
+	default:
+		return fmt.Errorf("%w: unsupported DNS record type %q", ErrInvalidConfig, c.Record)
+	}
+	if c.Server != "" {
+		if _, err := c.ServerAddress(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServerAddress returns the resolver as host:port, defaulting to port 53.
+func (c *DNSCheck) ServerAddress() (string, error) {
+	if _, _, err := net.SplitHostPort(c.Server); err == nil {
+		return c.Server, nil
+	}
+	if c.Server == "" || strings.ContainsAny(c.Server, "/ ") {
+		return "", fmt.Errorf("%w: invalid DNS server %q", ErrInvalidConfig, c.Server)
+	}
+	return net.JoinHostPort(strings.Trim(c.Server, "[]"), "53"), nil
+}
+
+// Verify compares the answer with the expected values. Names are compared
+// case-insensitively and without the trailing dot.
+func (c *DNSCheck) Verify(answers []string) error {
+	if len(answers) == 0 {
+		return fmt.Errorf("no %s records", c.Record)
+	}
+
+	got := make(map[string]bool, len(answers))
+	for _, a := range answers {
+		got[normalizeDNSValue(a)] = true
+	}
+
+	for _, want := range c.Expected {
+		if !got[normalizeDNSValue(want)] {
+			return fmt.Errorf("%s record %q not found in %v", c.Record, want, answers)
+		}
+	}
+	return nil
+}
+
+func normalizeDNSValue(v string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(v), "."))
+}
+
+// DNSName extracts the name to resolve from a dns://name target.
+func DNSName(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "dns" || u.Host == "" {
+		return "", fmt.Errorf("%w: dns target must look like dns://name, got %q", ErrInvalidConfig, target)
+	}
+	return u.Host, nil
+}
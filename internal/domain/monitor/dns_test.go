@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDNSCheck_Verify(t *testing.T) {
+	check := &DNSCheck{Record: DNSRecordMX, Expected: []string{"mail.example.com"}}
+
+	if err := check.Verify([]string{"MAIL.example.com.", "backup.example.com."}); err != nil {
+		t.Errorf("expected match, got %v", err)
+	}
+	if err := check.Verify([]string{"other.example.com."}); err == nil {
+		t.Error("expected mismatch error")
+	}
+	if err := check.Verify(nil); err == nil {
+		t.Error("expected error for empty answer")
+	}
+}
+
+func TestDNSCheck_ServerAddress(t *testing.T) {
+	cases := map[string]string{
+		"1.1.1.1":        "1.1.1.1:53",
+		"127.0.0.1:5353": "127.0.0.1:5353",
+		"[::1]":          "[::1]:53",
+	}
+	for server, want := range cases {
+		check := &DNSCheck{Server: server}
+		if got, err := check.ServerAddress(); err != nil || got != want {
+			t.Errorf("ServerAddress(%q) = %q, %v; want %q", server, got, err, want)
+		}
+	}
+}
+
+func TestURLMonitor_SetDNSCheck(t *testing.T) {
+	m := NewURLMonitor("dns://example.com", 5*time.Minute)
+	if m.Type != TypeDNS {
+		t.Errorf("expected type dns, got %s", m.Type)
+	}
+
+	if err := m.SetDNSCheck(&DNSCheck{Record: "mx"}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if m.DNS.Record != DNSRecordMX {
+		t.Errorf("expected record type to be normalized, got %s", m.DNS.Record)
+	}
+
+	if err := m.SetDNSCheck(&DNSCheck{Record: "SRV"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
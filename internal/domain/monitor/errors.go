@@ -0,0 +1,13 @@
+package monitor
+
+import (
+	"errors"
+)
+
+// ErrInvalidConfig is wrapped by every validation error so callers can tell
+// bad input apart from storage failures.
+var ErrInvalidConfig = errors.New("invalid monitor configuration")
+
+// ErrNotFound is wrapped by repositories when a record does not exist, so
+// callers can tell it apart from storage failures.
+var ErrNotFound = errors.New("not found")
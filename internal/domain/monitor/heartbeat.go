@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+type PingKind string
+
+const (
+	PingStart   PingKind = "start"
+	PingSuccess PingKind = "success"
+	PingFail    PingKind = "fail"
+)
+
+// ParsePingKind reads the optional suffix of a ping URL; no suffix means
+// success.
+func ParsePingKind(s string) (PingKind, error) {
+	switch kind := PingKind(s); kind {
+	case "":
+		return PingSuccess, nil
+	case PingStart, PingSuccess, PingFail:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("%w: unknown ping kind %q", ErrInvalidConfig, s)
+	}
+}
+
+// Heartbeat is the configuration and state of a push monitor. The target
+// pings with Token and the monitor goes down when no success or fail ping
+// arrives within Interval + Grace, or when the last ping reported failure.
+type Heartbeat struct {
+	Token      string
+	Grace      time.Duration
+	LastPing   *time.Time
+	LastStart  *time.Time
+	LastFailed bool
+}
+
+// SetHeartbeat configures the grace period of a heartbeat monitor and
+// issues its ping token. Other monitor types must not have a grace period.
+func (u *URLMonitor) SetHeartbeat(grace time.Duration) error {
+	if u.Type != TypeHeartbeat {
+		if grace != 0 {
+			return fmt.Errorf("%w: grace period is only supported by heartbeat monitors", ErrInvalidConfig)
+		}
+		u.Heartbeat = nil
+		return nil
+	}
+	if grace < 0 {
+		return fmt.Errorf("%w: grace period must not be negative", ErrInvalidConfig)
+	}
+
+	if u.Heartbeat == nil {
+		token, err := newToken()
+		if err != nil {
+			return err
+		}
+		u.Heartbeat = &Heartbeat{Token: token}
+	}
+	u.Heartbeat.Grace = grace
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// RecordPing applies a ping received at the given time. Start pings only
+// mark the job as running; success and fail pings produce a check result,
+// whose response time is the run duration when a start ping preceded it.
+func (u *URLMonitor) RecordPing(kind PingKind, log string, at time.Time) *CheckResult {
+	if kind == PingStart {
+		u.Heartbeat.LastStart = &at
+		return nil
+	}
+
+	result := NewCheckResult(u.ID, at)
+	result.Log = log
+	result.Status = StatusUp
+	if kind == PingFail {
+		result.Status = StatusDown
+		result.Error = "job reported failure"
+		result.ErrorKind = ErrorKindTarget
+	}
+	if start := u.Heartbeat.LastStart; start != nil {
+		result.ResponseTime = at.Sub(*start)
+		u.Heartbeat.LastStart = nil
+	}
+
+	u.Heartbeat.LastPing = &at
+	u.Heartbeat.LastFailed = kind == PingFail
+	u.LastChecked = &at
+	u.LastStatus = result.Status
+	return result
+}
+
+// CheckHeartbeat returns an error when the monitor's last ping failed or
+// the next one is overdue at now.
+func (u *URLMonitor) CheckHeartbeat(now time.Time) error {
+	if u.Heartbeat == nil {
+		return fmt.Errorf("heartbeat monitor has no token")
+	}
+	if u.Heartbeat.LastFailed {
+		return fmt.Errorf("last run reported failure")
+	}
+
+	if deadline := u.HeartbeatDeadline(); now.After(deadline) {
+		return fmt.Errorf("no ping since %s (expected by %s)", u.lastPing().Format(time.RFC3339), deadline.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// HeartbeatDeadline is when the monitor goes down unless a ping arrives:
+// Interval + Grace after the last ping, or after creation before any.
+func (u *URLMonitor) HeartbeatDeadline() time.Time {
+	var grace time.Duration
+	if u.Heartbeat != nil {
+		grace = u.Heartbeat.Grace
+	}
+	return u.lastPing().Add(u.Interval + grace)
+}
+
+func (u *URLMonitor) lastPing() time.Time {
+	if u.Heartbeat != nil && u.Heartbeat.LastPing != nil {
+		return *u.Heartbeat.LastPing
+	}
+	return u.CreatedAt
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
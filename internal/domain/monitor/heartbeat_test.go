@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newHeartbeatMonitor(t *testing.T) *URLMonitor {
+	m := NewURLMonitor("", time.Hour)
+	if err := m.SetType(TypeHeartbeat); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := m.SetHeartbeat(10 * time.Minute); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return m
+}
+
+func TestURLMonitor_SetHeartbeat(t *testing.T) {
+	m := newHeartbeatMonitor(t)
+
+	token := m.Heartbeat.Token
+	if len(token) != 32 {
+		t.Errorf("expected a 32-character token, got %q", token)
+	}
+	m.SetHeartbeat(time.Minute)
+	if m.Heartbeat.Token != token || m.Heartbeat.Grace != time.Minute {
+		t.Errorf("expected token to survive updates, got %+v", m.Heartbeat)
+	}
+
+	plain := NewURLMonitor("https://example.com", time.Minute)
+	if err := plain.SetHeartbeat(time.Minute); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for grace on http monitor, got %v", err)
+	}
+}
+
+func TestURLMonitor_RecordPing(t *testing.T) {
+	m := newHeartbeatMonitor(t)
+	start := time.Now()
+
+	if result := m.RecordPing(PingStart, "", start); result != nil {
+		t.Errorf("expected no result for start ping, got %+v", result)
+	}
+
+	result := m.RecordPing(PingSuccess, "42 rows", start.Add(90*time.Second))
+	if result.Status != StatusUp || result.Log != "42 rows" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	// Длительность выполнения задачи — от start до success
+	if result.ResponseTime != 90*time.Second {
+		t.Errorf("expected run duration 90s, got %v", result.ResponseTime)
+	}
+
+	result = m.RecordPing(PingFail, "disk full", start.Add(2*time.Minute))
+	if result.Status != StatusDown || m.LastStatus != StatusDown || result.ResponseTime != 0 {
+		t.Errorf("expected fail ping to mark the monitor down, got %+v", result)
+	}
+}
+
+func TestURLMonitor_CheckHeartbeat(t *testing.T) {
+	m := newHeartbeatMonitor(t)
+	now := m.CreatedAt
+
+	if err := m.CheckHeartbeat(now.Add(time.Hour + 5*time.Minute)); err != nil {
+		t.Errorf("expected ping to be within grace, got %v", err)
+	}
+	if err := m.CheckHeartbeat(now.Add(time.Hour + 11*time.Minute)); err == nil {
+		t.Error("expected overdue error after interval + grace")
+	}
+
+	m.RecordPing(PingSuccess, "", now.Add(time.Hour))
+	if err := m.CheckHeartbeat(now.Add(time.Hour + 11*time.Minute)); err != nil {
+		t.Errorf("expected a fresh ping to reset the deadline, got %v", err)
+	}
+
+	m.RecordPing(PingFail, "", now.Add(time.Hour+20*time.Minute))
+	if err := m.CheckHeartbeat(now.Add(time.Hour + 21*time.Minute)); err == nil {
+		t.Error("expected error after a fail ping")
+	}
+}
+
+func TestParsePingKind(t *testing.T) {
+	if kind, err := ParsePingKind(""); err != nil || kind != PingSuccess {
+		t.Errorf("expected plain ping to mean success, got %s (%v)", kind, err)
+	}
+	if _, err := ParsePingKind("restart"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
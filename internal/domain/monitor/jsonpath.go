@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath accepts the subset of JSONPath used by assertions:
+// $.a.b[0]['c d'] — dotted keys, quoted keys and array indexes.
+func parseJSONPath(path string) ([]pathSegment, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("%w: jsonpath %q must start with $", ErrInvalidConfig, path)
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("%w: jsonpath %q has an empty key", ErrInvalidConfig, path)
+			}
+			segments = append(segments, pathSegment{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("%w: jsonpath %q has an unclosed bracket", ErrInvalidConfig, path)
+			}
+			token := strings.TrimSpace(rest[1:end])
+			if len(token) >= 2 && (token[0] == '\'' || token[0] == '"') && token[len(token)-1] == token[0] {
+				segments = append(segments, pathSegment{key: token[1 : len(token)-1]})
+			} else {
+				index, err := strconv.Atoi(token)
+				if err != nil {
+					return nil, fmt.Errorf("%w: jsonpath %q has an invalid index %q", ErrInvalidConfig, path, token)
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%w: jsonpath %q has unexpected %q", ErrInvalidConfig, path, rest[0])
+		}
+	}
+
+	return segments, nil
+}
+
+// EvalJSONPath resolves path against a document decoded with encoding/json.
+func EvalJSONPath(doc any, path string) (any, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for _, seg := range segments {
+		if seg.isIndex {
+			arr, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot index non-array with %d", seg.index)
+			}
+			index := seg.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("index %d out of range", seg.index)
+			}
+			current = arr[index]
+			continue
+		}
+
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot read key %q from non-object", seg.key)
+		}
+		value, ok := obj[seg.key]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", seg.key)
+		}
+		current = value
+	}
+
+	return current, nil
+}
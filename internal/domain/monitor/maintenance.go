@@ -0,0 +1,171 @@
+package monitor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaintenanceMode decides what happens to checks during a window.
+type MaintenanceMode string
+
+const (
+	// MaintenanceSkip does not run checks at all.
+	MaintenanceSkip MaintenanceMode = "skip"
+	// MaintenanceFlag runs checks but marks their results as in maintenance.
+	MaintenanceFlag MaintenanceMode = "flag"
+)
+
+// MaintenanceWindow is planned downtime for the monitors it names, or that
+// carry one of its tags. A one-off window runs from Start to End; a
+// recurring one starts at every run of Recurrence and lasts Duration.
+type MaintenanceWindow struct {
+	ID         string
+	Name       string
+	Mode       MaintenanceMode
+	MonitorIDs []string
+	Tags       []string
+	Start      time.Time
+	End        time.Time
+	Recurrence *CronSchedule
+	Duration   time.Duration
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewMaintenanceWindow(name string) *MaintenanceWindow {
+	now := time.Now()
+	return &MaintenanceWindow{
+		ID:        generateID(),
+		Name:      name,
+		Mode:      MaintenanceFlag,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// SetMode sets the window's mode; empty means flag.
+func (w *MaintenanceWindow) SetMode(mode MaintenanceMode) error {
+	switch mode {
+	case "":
+		mode = MaintenanceFlag
+	case MaintenanceSkip, MaintenanceFlag:
+	default:
+		return fmt.Errorf("%w: unknown maintenance mode %q", ErrInvalidConfig, mode)
+	}
+	w.Mode = mode
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetScope names the monitors the window applies to, directly or by tag.
+// A window must have a scope so that it never silences everything by
+// accident.
+func (w *MaintenanceWindow) SetScope(monitorIDs, tags []string) error {
+	if len(monitorIDs) == 0 && len(tags) == 0 {
+		return fmt.Errorf("%w: maintenance window needs monitors or tags", ErrInvalidConfig)
+	}
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	w.MonitorIDs = monitorIDs
+	w.Tags = normalized
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetTimes makes the window one-off when recurrence is nil, and recurring
+// otherwise.
+func (w *MaintenanceWindow) SetTimes(start, end time.Time, recurrence *CronSchedule, duration time.Duration) error {
+	if recurrence == nil {
+		if start.IsZero() || !end.After(start) {
+			return fmt.Errorf("%w: a one-off maintenance window needs a start before its end", ErrInvalidConfig)
+		}
+		if duration != 0 {
+			return fmt.Errorf("%w: duration is only used by recurring maintenance windows", ErrInvalidConfig)
+		}
+	} else {
+		if !start.IsZero() || !end.IsZero() {
+			return fmt.Errorf("%w: a recurring maintenance window takes a duration, not start and end", ErrInvalidConfig)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("%w: a recurring maintenance window needs a positive duration", ErrInvalidConfig)
+		}
+		if err := recurrence.Validate(); err != nil {
+			return err
+		}
+	}
+
+	w.Start = start
+	w.End = end
+	w.Recurrence = recurrence
+	w.Duration = duration
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// ActiveAt reports whether the window is open at t.
+func (w *MaintenanceWindow) ActiveAt(t time.Time) bool {
+	if w.Recurrence == nil {
+		return !t.Before(w.Start) && t.Before(w.End)
+	}
+	// The only start that can still cover t is the first one after t-Duration.
+	start := w.Recurrence.Next(t.Add(-w.Duration))
+	return !start.IsZero() && !start.After(t)
+}
+
+// Covers reports whether the window applies to m.
+func (w *MaintenanceWindow) Covers(m *URLMonitor) bool {
+	if slices.Contains(w.MonitorIDs, m.ID) {
+		return true
+	}
+	for _, tag := range w.Tags {
+		if slices.Contains(m.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveMaintenance returns the window covering m at t, preferring one
+// that skips checks, or nil when m is not in maintenance.
+func ActiveMaintenance(windows []*MaintenanceWindow, m *URLMonitor, t time.Time) *MaintenanceWindow {
+	var active *MaintenanceWindow
+	for _, w := range windows {
+		if !w.Covers(m) || !w.ActiveAt(t) {
+			continue
+		}
+		if active == nil || w.Mode == MaintenanceSkip {
+			active = w
+		}
+	}
+	return active
+}
+
+// SetTags replaces the monitor's tags, which maintenance windows can
+// select it by. Tags are trimmed, lowercased and deduplicated.
+func (u *URLMonitor) SetTags(tags []string) error {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	u.Tags = normalized
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, fmt.Errorf("%w: tags must not be empty", ErrInvalidConfig)
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
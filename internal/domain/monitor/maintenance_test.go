@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow_ActiveAt_OneOff(t *testing.T) {
+	start := time.Date(2024, 5, 1, 22, 0, 0, 0, time.UTC)
+	w := NewMaintenanceWindow("deploy")
+	if err := w.SetTimes(start, start.Add(time.Hour), nil, 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if w.ActiveAt(start.Add(-time.Second)) || !w.ActiveAt(start) || !w.ActiveAt(start.Add(59*time.Minute)) || w.ActiveAt(start.Add(time.Hour)) {
+		t.Error("expected window to cover [start, end)")
+	}
+}
+
+func TestMaintenanceWindow_ActiveAt_Recurring(t *testing.T) {
+	w := NewMaintenanceWindow("nightly backup")
+	// Каждую субботу с 02:00 на полтора часа
+	if err := w.SetTimes(time.Time{}, time.Time{}, &CronSchedule{Expression: "0 2 * * SAT"}, 90*time.Minute); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saturday := time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{saturday.Add(time.Hour + 59*time.Minute), false},
+		{saturday.Add(2 * time.Hour), true},
+		{saturday.Add(3*time.Hour + 29*time.Minute), true},
+		{saturday.Add(3*time.Hour + 30*time.Minute), false},
+		{saturday.Add(24*time.Hour + 2*time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := w.ActiveAt(tt.at); got != tt.want {
+			t.Errorf("ActiveAt(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceWindow_Validation(t *testing.T) {
+	w := NewMaintenanceWindow("x")
+	now := time.Now()
+
+	if err := w.SetScope(nil, nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for window without scope, got %v", err)
+	}
+	if err := w.SetMode("ignore"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for unknown mode, got %v", err)
+	}
+	if err := w.SetTimes(now, now, nil, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for empty window, got %v", err)
+	}
+	if err := w.SetTimes(time.Time{}, time.Time{}, &CronSchedule{Expression: "@daily"}, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for recurring window without duration, got %v", err)
+	}
+	if err := w.SetTimes(now, now.Add(time.Hour), &CronSchedule{Expression: "@daily"}, time.Hour); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for recurring window with start and end, got %v", err)
+	}
+}
+
+func TestActiveMaintenance(t *testing.T) {
+	now := time.Now()
+	m := NewURLMonitor("https://example.com", time.Minute)
+	m.SetTags([]string{" Payments ", "payments", "eu"})
+
+	if len(m.Tags) != 2 || m.Tags[0] != "payments" {
+		t.Fatalf("expected normalized tags, got %v", m.Tags)
+	}
+
+	byTag := NewMaintenanceWindow("eu deploy")
+	byTag.SetScope(nil, []string{"EU"})
+	byTag.SetTimes(now.Add(-time.Minute), now.Add(time.Hour), nil, 0)
+
+	byID := NewMaintenanceWindow("db migration")
+	byID.SetMode(MaintenanceSkip)
+	byID.SetScope([]string{m.ID}, nil)
+	byID.SetTimes(now.Add(-time.Minute), now.Add(time.Hour), nil, 0)
+
+	other := NewMaintenanceWindow("other")
+	other.SetMode(MaintenanceSkip)
+	other.SetScope([]string{"someone-else"}, []string{"us"})
+	other.SetTimes(now.Add(-time.Minute), now.Add(time.Hour), nil, 0)
+
+	if got := ActiveMaintenance([]*MaintenanceWindow{byTag, other}, m, now); got != byTag {
+		t.Errorf("expected window matched by tag, got %+v", got)
+	}
+	// Окно skip важнее окна flag
+	if got := ActiveMaintenance([]*MaintenanceWindow{byTag, byID}, m, now); got != byID {
+		t.Errorf("expected skip window to win, got %+v", got)
+	}
+	if got := ActiveMaintenance([]*MaintenanceWindow{byTag}, m, now.Add(2*time.Hour)); got != nil {
+		t.Errorf("expected no window after it closed, got %+v", got)
+	}
+}
@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+type Type string
+
+const (
+	TypeHTTP Type = "http"
+	TypeDNS  Type = "dns"
+
+	// Socket monitors send Body as a raw payload, if set, and evaluate
+	// Assertions against the reply or the banner sent on connect.
+	TypeTCP Type = "tcp"
+	TypeUDP Type = "udp"
+
+	// TypeWebSocket performs the upgrade handshake on a ws:// or wss://
+	// target. Body, if set, is sent as a text message and Assertions are
+	// evaluated against the first message received.
+	TypeWebSocket Type = "websocket"
+
+	// TypeHeartbeat is passive: the target pings the service instead of
+	// being probed, and its URL is ignored.
+	TypeHeartbeat Type = "heartbeat"
+
+	// TypeTransaction runs a sequence of http steps. Its URL, if set, is
+	// the base that relative step URLs resolve against.
+	TypeTransaction Type = "transaction"
+)
+
+// TypeForURL guesses the monitor type from the target's scheme so that
+// plain URLs keep creating HTTP monitors.
+func TypeForURL(target string) Type {
+	switch {
+	case strings.HasPrefix(strings.ToLower(target), "tcp://"):
+		return TypeTCP
+	case strings.HasPrefix(strings.ToLower(target), "udp://"):
+		return TypeUDP
+	case strings.HasPrefix(strings.ToLower(target), "dns://"):
+		return TypeDNS
+	case strings.HasPrefix(strings.ToLower(target), "ws://"), strings.HasPrefix(strings.ToLower(target), "wss://"):
+		return TypeWebSocket
+	}
+	return TypeHTTP
+}
+
+func (t Type) validateTarget(target string) error {
+	switch t {
+	case TypeHTTP:
+		return nil
+	case TypeTCP:
+		_, err := TCPAddress(target)
+		return err
+	case TypeUDP:
+		_, err := UDPAddress(target)
+		return err
+	case TypeDNS:
+		_, err := DNSName(target)
+		return err
+	case TypeWebSocket:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			return fmt.Errorf("%w: websocket target must look like ws://host/path or wss://host/path, got %q", ErrInvalidConfig, target)
+		}
+		return nil
+	case TypeHeartbeat:
+		return nil
+	case TypeTransaction:
+		if target == "" {
+			return nil
+		}
+		if u, err := url.Parse(target); err != nil || !u.IsAbs() {
+			return fmt.Errorf("%w: transaction base URL must be absolute, got %q", ErrInvalidConfig, target)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown monitor type %q", ErrInvalidConfig, t)
+	}
+}
+
+// TCPAddress extracts host:port from a tcp://host:port target.
+func TCPAddress(target string) (string, error) {
+	return socketAddress("tcp", target)
+}
+
+// UDPAddress extracts host:port from a udp://host:port target.
+func UDPAddress(target string) (string, error) {
+	return socketAddress("udp", target)
+}
+
+func socketAddress(scheme, target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != scheme {
+		return "", fmt.Errorf("%w: %s target must look like %s://host:port, got %q", ErrInvalidConfig, scheme, scheme, target)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil || host == "" || port == "" {
+		return "", fmt.Errorf("%w: %s target %q needs a host and port", ErrInvalidConfig, scheme, target)
+	}
+	return u.Host, nil
+}
@@ -0,0 +1,29 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ProxyDirect makes a monitor bypass the configured default proxy.
+const ProxyDirect = "direct"
+
+// ValidateProxy accepts an http, https or socks5 proxy URL, or ProxyDirect.
+func ValidateProxy(proxy string) error {
+	if proxy == "" || proxy == ProxyDirect {
+		return nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("%w: invalid proxy URL %q", ErrInvalidConfig, proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("%w: proxy scheme must be http, https or socks5, got %q", ErrInvalidConfig, u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: proxy URL %q needs a host", ErrInvalidConfig, proxy)
+	}
+	return nil
+}
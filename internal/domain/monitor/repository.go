@@ -1,9 +1,57 @@
 package monitor
 
+import (
+	"time"
+)
+
+// Repository stores monitors, the maintenance windows scoped to them and
+// the leases checker instances hold on them.
 type Repository interface {
 	Save(monitor *URLMonitor) error
 	FindByID(id string) (*URLMonitor, error)
 	FindAll() ([]*URLMonitor, error)
+	// FindUpdatedSince returns the monitors created or edited at or after t.
+	FindUpdatedSince(t time.Time) ([]*URLMonitor, error)
+	FindByHeartbeatToken(token string) (*URLMonitor, error)
 	Delete(id string) error
 	Update(monitor *URLMonitor) error
+	// UpdateCheckState saves only what a check changes: LastChecked,
+	// LastStatus, Certificate and the watch's LastHash. Edits and pings
+	// made while the check ran are kept.
+	UpdateCheckState(monitor *URLMonitor) error
+	// UpdateHeartbeat saves only the heartbeat state, LastChecked and
+	// LastStatus after a ping.
+	UpdateHeartbeat(monitor *URLMonitor) error
+	MaintenanceRepository
+	LeaseRepository
+}
+
+type MaintenanceRepository interface {
+	SaveWindow(window *MaintenanceWindow) error
+	UpdateWindow(window *MaintenanceWindow) error
+	FindWindow(id string) (*MaintenanceWindow, error)
+	FindWindows() ([]*MaintenanceWindow, error)
+	DeleteWindow(id string) error
+}
+
+// ResultRepository stores the history of checks and the content snapshots
+// of watch monitors. A zero from or to leaves that side of the range open.
+// Snapshots are listed oldest first.
+type ResultRepository interface {
+	SaveResult(result *CheckResult) error
+	FindResults(monitorID string, from, to time.Time) ([]*CheckResult, error)
+	SaveSnapshot(snapshot *Snapshot) error
+	FindSnapshots(monitorID string) ([]*Snapshot, error)
+	FindSnapshot(monitorID string, id int64) (*Snapshot, error)
+}
+
+// LeaseRepository coordinates checker instances sharing a repository. A
+// lease gives its owner the right to check a monitor until it expires.
+type LeaseRepository interface {
+	// AcquireLease takes or extends the lease on a monitor for ttl from now.
+	// It reports false when another owner holds an unexpired lease.
+	AcquireLease(monitorID, owner string, now time.Time, ttl time.Duration) (bool, error)
+	// RenewLeases extends every lease held by owner.
+	RenewLeases(owner string, now time.Time, ttl time.Duration) error
+	ReleaseLeases(owner string) error
 }
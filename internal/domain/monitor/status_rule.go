@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const DefaultExpectedStatus = "200-399"
+
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusWarning  Status = "warning"
+	StatusDown     Status = "down"
+)
+
+var statusSeverity = map[Status]int{
+	StatusUp:       0,
+	StatusDegraded: 1,
+	StatusWarning:  2,
+	StatusDown:     3,
+}
+
+// Worse returns the more severe of two statuses.
+func Worse(a, b Status) Status {
+	if statusSeverity[b] > statusSeverity[a] {
+		return b
+	}
+	return a
+}
+
+type statusRange struct {
+	from, to int
+}
+
+// StatusRule is a parsed acceptance list such as "200-299,301".
+type StatusRule []statusRange
+
+func ParseStatusRule(expr string) (StatusRule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		expr = DefaultExpectedStatus
+	}
+
+	var rule StatusRule
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+
+		from, err := parseStatusCode(lo)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseStatusCode(hi); err != nil {
+				return nil, err
+			}
+		}
+		if to < from {
+			return nil, fmt.Errorf("%w: invalid status range %q", ErrInvalidConfig, part)
+		}
+
+		rule = append(rule, statusRange{from: from, to: to})
+	}
+
+	return rule, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("%w: invalid status code %q", ErrInvalidConfig, s)
+	}
+	return code, nil
+}
+
+func (r StatusRule) Matches(code int) bool {
+	for _, rng := range r {
+		if code >= rng.from && code <= rng.to {
+			return true
+		}
+	}
+	return false
+}
+
+func (r StatusRule) String() string {
+	parts := make([]string, len(r))
+	for i, rng := range r {
+		if rng.from == rng.to {
+			parts[i] = strconv.Itoa(rng.from)
+		} else {
+			parts[i] = fmt.Sprintf("%d-%d", rng.from, rng.to)
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseStatusRule(t *testing.T) {
+	rule, err := ParseStatusRule(" 200-299, 301 ")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rule.String() != "200-299,301" {
+		t.Errorf("expected normalized rule 200-299,301, got %s", rule.String())
+	}
+
+	cases := map[int]bool{200: true, 204: true, 299: true, 301: true, 302: false, 404: false, 500: false}
+	for code, want := range cases {
+		if got := rule.Matches(code); got != want {
+			t.Errorf("Matches(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestParseStatusRule_Default(t *testing.T) {
+	rule, err := ParseStatusRule("")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !rule.Matches(302) || rule.Matches(404) {
+		t.Errorf("expected default rule %s, got %s", DefaultExpectedStatus, rule.String())
+	}
+}
+
+func TestParseStatusRule_Invalid(t *testing.T) {
+	for _, expr := range []string{"abc", "299-200", "200-", "99", "200,,301"} {
+		if _, err := ParseStatusRule(expr); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for %q, got %v", expr, err)
+		}
+	}
+}
+
+func TestWorse(t *testing.T) {
+	if Worse(StatusUp, StatusDegraded) != StatusDegraded {
+		t.Error("expected degraded to be worse than up")
+	}
+	if Worse(StatusDown, StatusWarning) != StatusDown {
+		t.Error("expected down to be worse than warning")
+	}
+}
@@ -0,0 +1,23 @@
+package monitor
+
+import "fmt"
+
+// TLSConfig is the TLS profile of an https monitor. CertFile and KeyFile
+// hold a PEM client certificate for mTLS, CAFile a PEM bundle trusted
+// instead of the system roots, and ServerName overrides SNI and the name
+// the server certificate is verified against. Monitors with equal profiles
+// share a transport.
+type TLSConfig struct {
+	CertFile           string
+	KeyFile            string
+	CAFile             string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+func (c *TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("%w: client certificate and key must be set together", ErrInvalidConfig)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTLSConfig_Validate(t *testing.T) {
+	if err := (&TLSConfig{CertFile: "client.pem", KeyFile: "client-key.pem"}).Validate(); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+	if err := (&TLSConfig{CertFile: "client.pem"}).Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for cert without key, got %v", err)
+	}
+}
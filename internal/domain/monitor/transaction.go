@@ -0,0 +1,237 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const MaxTransactionSteps = 20
+
+type ExtractSource string
+
+const (
+	ExtractJSONPath ExtractSource = "jsonpath"
+	ExtractRegex    ExtractSource = "regex"
+	ExtractHeader   ExtractSource = "header"
+)
+
+var (
+	variablePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	referencePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+)
+
+// Extraction stores part of a step's response in Variable for later steps,
+// which reference it as {{Variable}}. Expression is a JSONPath, a regex whose
+// first group (or whole match) is taken, or a header name.
+type Extraction struct {
+	Variable   string
+	Source     ExtractSource
+	Expression string
+}
+
+func (e Extraction) Validate() error {
+	if !variablePattern.MatchString(e.Variable) {
+		return fmt.Errorf("%w: invalid variable name %q", ErrInvalidConfig, e.Variable)
+	}
+	switch e.Source {
+	case ExtractJSONPath:
+		if _, err := parseJSONPath(e.Expression); err != nil {
+			return err
+		}
+	case ExtractRegex:
+		if _, err := regexp.Compile(e.Expression); err != nil {
+			return fmt.Errorf("%w: invalid regex %q: %v", ErrInvalidConfig, e.Expression, err)
+		}
+	case ExtractHeader:
+		if e.Expression == "" {
+			return fmt.Errorf("%w: header extraction needs a header name", ErrInvalidConfig)
+		}
+	default:
+		return fmt.Errorf("%w: unknown extraction source %q", ErrInvalidConfig, e.Source)
+	}
+	return nil
+}
+
+// Extract reads the variable's value from a response.
+func (e Extraction) Extract(header http.Header, body []byte) (string, error) {
+	switch e.Source {
+	case ExtractJSONPath:
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return "", fmt.Errorf("body is not valid JSON: %v", err)
+		}
+		value, err := EvalJSONPath(doc, e.Expression)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", e.Expression, err)
+		}
+		return JSONValueString(value), nil
+	case ExtractRegex:
+		re, err := regexp.Compile(e.Expression)
+		if err != nil {
+			return "", err
+		}
+		match := re.FindSubmatch(body)
+		if match == nil {
+			return "", fmt.Errorf("body does not match %q", e.Expression)
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	case ExtractHeader:
+		value := header.Get(e.Expression)
+		if value == "" {
+			return "", fmt.Errorf("header %s is missing", e.Expression)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown extraction source %q", e.Source)
+}
+
+// TransactionStep is one request of a transaction monitor. URL may be
+// relative to the monitor's URL; URL, Headers and Body may reference
+// variables extracted by earlier steps.
+type TransactionStep struct {
+	Name           string
+	Method         string
+	URL            string
+	Headers        map[string]string
+	Body           string
+	ExpectedStatus string
+	Assertions     []BodyAssertion
+	Extract        []Extraction
+}
+
+func (s TransactionStep) Validate() error {
+	if s.URL == "" {
+		return fmt.Errorf("%w: step %q needs a URL", ErrInvalidConfig, s.Name)
+	}
+	if s.Method != "" && !allowedMethods[strings.ToUpper(s.Method)] {
+		return fmt.Errorf("%w: step %q has unsupported method %q", ErrInvalidConfig, s.Name, s.Method)
+	}
+	if _, err := ParseStatusRule(s.ExpectedStatus); err != nil {
+		return err
+	}
+	for _, a := range s.Assertions {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, e := range s.Extract {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// references lists the variables the step's request uses.
+func (s TransactionStep) references() []string {
+	texts := []string{s.URL, s.Body}
+	for name, value := range s.Headers {
+		texts = append(texts, name, value)
+	}
+
+	var names []string
+	for _, text := range texts {
+		for _, match := range referencePattern.FindAllStringSubmatch(text, -1) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Expand returns the step with variable references replaced and its URL
+// resolved against base.
+func (s TransactionStep) Expand(base string, vars map[string]string) (TransactionStep, error) {
+	var missing string
+	expand := func(text string) string {
+		return referencePattern.ReplaceAllStringFunc(text, func(ref string) string {
+			name := referencePattern.FindStringSubmatch(ref)[1]
+			value, ok := vars[name]
+			if !ok {
+				missing = name
+			}
+			return value
+		})
+	}
+
+	expanded := s
+	expanded.URL = expand(s.URL)
+	expanded.Body = expand(s.Body)
+	expanded.Headers = make(map[string]string, len(s.Headers))
+	for name, value := range s.Headers {
+		expanded.Headers[expand(name)] = expand(value)
+	}
+	if missing != "" {
+		return s, fmt.Errorf("variable %q is not defined", missing)
+	}
+
+	if base != "" {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return s, err
+		}
+		ref, err := url.Parse(expanded.URL)
+		if err != nil {
+			return s, err
+		}
+		expanded.URL = baseURL.ResolveReference(ref).String()
+	}
+	return expanded, nil
+}
+
+// StepResult is the outcome of one transaction step within a check.
+type StepResult struct {
+	Name            string
+	URL             string
+	StatusCode      int
+	ResponseTime    time.Duration
+	Error           string
+	FailedAssertion string
+}
+
+// SetSteps configures the requests of a transaction monitor. Every variable
+// a step references must be extracted by an earlier step.
+func (u *URLMonitor) SetSteps(steps []TransactionStep) error {
+	if u.Type != TypeTransaction {
+		if len(steps) > 0 {
+			return fmt.Errorf("%w: steps are only supported by transaction monitors", ErrInvalidConfig)
+		}
+		u.Steps = nil
+		return nil
+	}
+	if len(steps) == 0 || len(steps) > MaxTransactionSteps {
+		return fmt.Errorf("%w: a transaction needs between 1 and %d steps", ErrInvalidConfig, MaxTransactionSteps)
+	}
+
+	normalized := make([]TransactionStep, len(steps))
+	defined := map[string]bool{}
+	for i, step := range steps {
+		if err := step.Validate(); err != nil {
+			return err
+		}
+		step.Method = strings.ToUpper(step.Method)
+		if step.Method == "" {
+			step.Method = DefaultMethod
+		}
+		normalized[i] = step
+		for _, name := range step.references() {
+			if !defined[name] {
+				return fmt.Errorf("%w: step %d uses variable %q before it is extracted", ErrInvalidConfig, i+1, name)
+			}
+		}
+		for _, e := range step.Extract {
+			defined[e.Variable] = true
+		}
+	}
+
+	u.Steps = normalized
+	u.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func loginFlow() []TransactionStep {
+	return []TransactionStep{
+		{
+			Name:    "login",
+			Method:  "post",
+			URL:     "/login",
+			Extract: []Extraction{{Variable: "token", Source: ExtractJSONPath, Expression: "$.token"}},
+		},
+		{
+			Name:       "orders",
+			URL:        "/orders?user={{ token }}",
+			Headers:    map[string]string{"Authorization": "Bearer {{token}}"},
+			Assertions: []BodyAssertion{{Type: AssertJSONPath, Path: "$.orders", Operator: OpGreater, Value: "0"}},
+		},
+	}
+}
+
+func TestURLMonitor_SetSteps(t *testing.T) {
+	m := NewURLMonitor("https://shop.example.com", 5*time.Minute)
+	if err := m.SetType(TypeTransaction); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := m.SetSteps(loginFlow()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if m.Steps[0].Method != "POST" || m.Steps[1].Method != DefaultMethod {
+		t.Errorf("expected methods to be normalized, got %s / %s", m.Steps[0].Method, m.Steps[1].Method)
+	}
+
+	if err := m.SetSteps(nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for empty transaction, got %v", err)
+	}
+
+	// Переменная используется раньше, чем извлечена
+	reordered := loginFlow()
+	reordered[0], reordered[1] = reordered[1], reordered[0]
+	if err := m.SetSteps(reordered); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for undefined variable, got %v", err)
+	}
+
+	plain := NewURLMonitor("https://example.com", 5*time.Minute)
+	if err := plain.SetSteps(loginFlow()); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for steps on http monitor, got %v", err)
+	}
+}
+
+func TestTransactionStep_Expand(t *testing.T) {
+	step := loginFlow()[1]
+
+	expanded, err := step.Expand("https://shop.example.com/api/", map[string]string{"token": "abc"})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expanded.URL != "https://shop.example.com/orders?user=abc" {
+		t.Errorf("unexpected URL %s", expanded.URL)
+	}
+	if expanded.Headers["Authorization"] != "Bearer abc" {
+		t.Errorf("unexpected headers %v", expanded.Headers)
+	}
+	if step.Headers["Authorization"] != "Bearer {{token}}" {
+		t.Error("expected the original step to stay unchanged")
+	}
+
+	if _, err := step.Expand("", nil); err == nil {
+		t.Error("expected error for undefined variable")
+	}
+}
+
+func TestExtraction_Extract(t *testing.T) {
+	header := http.Header{"X-Session": []string{"s-1"}}
+	body := []byte(`{"token":"t-1","user":{"id":42}}`)
+
+	cases := []struct {
+		extraction Extraction
+		want       string
+	}{
+		{Extraction{Variable: "token", Source: ExtractJSONPath, Expression: "$.token"}, "t-1"},
+		{Extraction{Variable: "id", Source: ExtractJSONPath, Expression: "$.user.id"}, "42"},
+		{Extraction{Variable: "id", Source: ExtractRegex, Expression: `"id":(\d+)`}, "42"},
+		{Extraction{Variable: "session", Source: ExtractHeader, Expression: "x-session"}, "s-1"},
+	}
+	for _, c := range cases {
+		got, err := c.extraction.Extract(header, body)
+		if err != nil || got != c.want {
+			t.Errorf("%s %s: got %q, %v; want %q", c.extraction.Source, c.extraction.Expression, got, err, c.want)
+		}
+	}
+
+	if _, err := (Extraction{Variable: "x", Source: ExtractHeader, Expression: "X-Missing"}).Extract(header, body); err == nil {
+		t.Error("expected error for missing header")
+	}
+}
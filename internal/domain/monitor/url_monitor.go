@@ -1,28 +1,85 @@
 package monitor
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
+const (
+	DefaultMethod  = "GET"
+	DefaultTimeout = 10 * time.Second
+	MaxTimeout     = 5 * time.Minute
+	MaxRetries     = 10
+	MaxRetryDelay  = time.Minute
+
+	DefaultMaxRedirects = 10
+	MaxRedirectsLimit   = 30
+)
+
+var allowedMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"OPTIONS": true,
+}
+
 type URLMonitor struct {
-	ID          string
-	URL         string
-	Interval    time.Duration
-	IsActive    bool
-	LastChecked *time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID              string
+	Type            Type
+	URL             string
+	Interval        time.Duration
+	Cron            *CronSchedule
+	Tags            []string
+	Method          string
+	Headers         map[string]string
+	Body            string
+	Auth            *AuthConfig
+	TLS             *TLSConfig
+	Proxy           string
+	ExpectedStatus  string
+	Assertions      []BodyAssertion
+	CertExpiryDays  int
+	Certificate     *CertificateInfo
+	DNS             *DNSCheck
+	Steps           []TransactionStep
+	Heartbeat       *Heartbeat
+	Watch           *Watch
+	Timeout         time.Duration
+	SlowThreshold   time.Duration
+	Retries         int
+	RetryDelay      time.Duration
+	FollowRedirects bool
+	MaxRedirects    int
+	ExpectedURL     string
+	IsActive        bool
+	LastChecked     *time.Time
+	LastStatus      Status
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
-func NewURLMonitor(url string, interval time.Duration) *URLMonitor {
+func NewURLMonitor(target string, interval time.Duration) *URLMonitor {
 	now := time.Now()
 	return &URLMonitor{
-		ID:        generateID(),
-		URL:       url,
-		Interval:  interval,
-		IsActive:  true,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:              generateID(),
+		Type:            TypeForURL(target),
+		URL:             target,
+		Interval:        interval,
+		Method:          DefaultMethod,
+		Headers:         map[string]string{},
+		ExpectedStatus:  DefaultExpectedStatus,
+		CertExpiryDays:  DefaultCertExpiryDays,
+		Timeout:         DefaultTimeout,
+		FollowRedirects: true,
+		MaxRedirects:    DefaultMaxRedirects,
+		IsActive:        true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 }
 
@@ -36,12 +93,229 @@ func (u *URLMonitor) Resume() {
 	u.UpdatedAt = time.Now()
 }
 
-func (u *URLMonitor) Update(url string, interval time.Duration) {
-	u.URL = url
+func (u *URLMonitor) Update(target string, interval time.Duration) {
+	u.URL = target
 	u.Interval = interval
 	u.UpdatedAt = time.Now()
 }
 
+// SetType switches the monitor type; an empty type is inferred from the URL.
+func (u *URLMonitor) SetType(t Type) error {
+	if t == "" {
+		t = TypeForURL(u.URL)
+	}
+	if err := t.validateTarget(u.URL); err != nil {
+		return err
+	}
+	u.Type = t
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetTimeouts sets how long a check may take before it fails and, when slow
+// is non-zero, the latency above which a successful check is degraded.
+// A zero timeout means DefaultTimeout.
+func (u *URLMonitor) SetTimeouts(timeout, slow time.Duration) error {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	if timeout < 0 || timeout > MaxTimeout {
+		return fmt.Errorf("%w: timeout must be between 0 and %s", ErrInvalidConfig, MaxTimeout)
+	}
+	if slow < 0 {
+		return fmt.Errorf("%w: slow threshold must not be negative", ErrInvalidConfig)
+	}
+	u.Timeout = timeout
+	u.SlowThreshold = slow
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// LatencyStatus reports degraded when a check took longer than the
+// monitor's slow threshold.
+func (u *URLMonitor) LatencyStatus(responseTime time.Duration) Status {
+	if u.SlowThreshold > 0 && responseTime > u.SlowThreshold {
+		return StatusDegraded
+	}
+	return StatusUp
+}
+
+// SetAuth configures credentials for http monitors; nil disables auth.
+func (u *URLMonitor) SetAuth(auth *AuthConfig) error {
+	if auth != nil {
+		if err := auth.Validate(); err != nil {
+			return err
+		}
+	}
+	u.Auth = auth
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetTLSConfig sets the TLS profile for https monitors; nil uses the
+// default transport.
+func (u *URLMonitor) SetTLSConfig(config *TLSConfig) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+	u.TLS = config
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetProxy routes http and websocket checks through proxy. An empty proxy uses the
+// checker's default and ProxyDirect bypasses it.
+func (u *URLMonitor) SetProxy(proxy string) error {
+	if err := ValidateProxy(proxy); err != nil {
+		return err
+	}
+	u.Proxy = proxy
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetRetryPolicy makes the checker re-probe a failing target up to retries
+// more times, waiting delay between attempts, before declaring it down.
+func (u *URLMonitor) SetRetryPolicy(retries int, delay time.Duration) error {
+	if retries < 0 || retries > MaxRetries {
+		return fmt.Errorf("%w: retries must be between 0 and %d", ErrInvalidConfig, MaxRetries)
+	}
+	if delay < 0 || delay > MaxRetryDelay {
+		return fmt.Errorf("%w: retry delay must be between 0 and %s", ErrInvalidConfig, MaxRetryDelay)
+	}
+	u.Retries = retries
+	u.RetryDelay = delay
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetRedirectPolicy controls redirect handling for http monitors. A
+// non-positive max falls back to DefaultMaxRedirects. expectedURL is compared
+// with the final URL when redirects are followed and with the Location
+// header otherwise.
+func (u *URLMonitor) SetRedirectPolicy(follow bool, max int, expectedURL string) error {
+	if max <= 0 {
+		max = DefaultMaxRedirects
+	}
+	if max > MaxRedirectsLimit {
+		return fmt.Errorf("%w: at most %d redirects can be followed", ErrInvalidConfig, MaxRedirectsLimit)
+	}
+	if expectedURL != "" {
+		parsed, err := url.Parse(expectedURL)
+		if err != nil || !parsed.IsAbs() {
+			return fmt.Errorf("%w: expected URL %q must be absolute", ErrInvalidConfig, expectedURL)
+		}
+	}
+	u.FollowRedirects = follow
+	u.MaxRedirects = max
+	u.ExpectedURL = expectedURL
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetDNSCheck configures dns monitors; nil resets to an A lookup through the
+// system resolver.
+func (u *URLMonitor) SetDNSCheck(check *DNSCheck) error {
+	if check != nil {
+		check.Record = DNSRecordType(strings.ToUpper(string(check.Record)))
+		if check.Record == "" {
+			check.Record = DNSRecordA
+		}
+		if err := check.Validate(); err != nil {
+			return err
+		}
+	}
+	u.DNS = check
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (u *URLMonitor) SetRequest(method string, headers map[string]string, body string) error {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = DefaultMethod
+	}
+	if !allowedMethods[method] {
+		return fmt.Errorf("%w: unsupported method %q", ErrInvalidConfig, method)
+	}
+	if headers == nil {
+		headers = map[string]string{}
+	}
+
+	u.Method = method
+	u.Headers = headers
+	u.Body = body
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (u *URLMonitor) SetExpectedStatus(expr string) error {
+	rule, err := ParseStatusRule(expr)
+	if err != nil {
+		return err
+	}
+	u.ExpectedStatus = rule.String()
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (u *URLMonitor) SetAssertions(assertions []BodyAssertion) error {
+	for _, a := range assertions {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	u.Assertions = assertions
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetCertExpiryDays sets how many days before expiry a certificate turns the
+// monitor into a warning; 0 disables the warning.
+func (u *URLMonitor) SetCertExpiryDays(days int) error {
+	if days < 0 {
+		return fmt.Errorf("%w: certificate expiry threshold must not be negative", ErrInvalidConfig)
+	}
+	u.CertExpiryDays = days
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// CertificateStatus classifies the last seen certificate against the
+// monitor's threshold. Monitors without a certificate are always up.
+func (u *URLMonitor) CertificateStatus(now time.Time) Status {
+	if u.Certificate == nil {
+		return StatusUp
+	}
+	if now.After(u.Certificate.NotAfter) {
+		return StatusDown
+	}
+	if u.CertExpiryDays > 0 && u.Certificate.DaysUntilExpiry(now) < u.CertExpiryDays {
+		return StatusWarning
+	}
+	return StatusUp
+}
+
+// CheckBody runs every assertion and returns the first one that fails.
+func (u *URLMonitor) CheckBody(body []byte) (*BodyAssertion, error) {
+	for i := range u.Assertions {
+		if err := u.Assertions[i].Evaluate(body); err != nil {
+			return &u.Assertions[i], err
+		}
+	}
+	return nil, nil
+}
+
+func (u *URLMonitor) AcceptsStatus(code int) bool {
+	rule, err := ParseStatusRule(u.ExpectedStatus)
+	if err != nil {
+		return false
+	}
+	return rule.Matches(code)
+}
+
 func generateID() string {
 	return time.Now().Format("20060102150405") + "-" + randomString(8)
 }
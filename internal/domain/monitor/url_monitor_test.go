@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -60,3 +61,135 @@ func TestURLMonitor_Update(t *testing.T) {
 		t.Errorf("expected interval %v, got %v", newInterval, m.Interval)
 	}
 }
+
+func TestURLMonitor_SetRequest(t *testing.T) {
+	m := NewURLMonitor("https://example.com", 5*time.Minute)
+	if m.Method != "GET" {
+		t.Errorf("expected default method GET, got %s", m.Method)
+	}
+
+	err := m.SetRequest("post", map[string]string{"X-Api-Key": "secret"}, `{"ping":true}`)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if m.Method != "POST" {
+		t.Errorf("expected method POST, got %s", m.Method)
+	}
+	if m.Headers["X-Api-Key"] != "secret" {
+		t.Errorf("expected header to be set, got %v", m.Headers)
+	}
+	if m.Body != `{"ping":true}` {
+		t.Errorf("expected body to be set, got %s", m.Body)
+	}
+}
+
+func TestURLMonitor_SetRequest_InvalidMethod(t *testing.T) {
+	m := NewURLMonitor("https://example.com", 5*time.Minute)
+
+	err := m.SetRequest("FETCH", nil, "")
+
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+	if m.Method != "GET" {
+		t.Errorf("expected method to stay GET, got %s", m.Method)
+	}
+}
+
+func TestURLMonitor_SetType(t *testing.T) {
+	m := NewURLMonitor("tcp://db.internal:5432", 5*time.Minute)
+	if m.Type != TypeTCP {
+		t.Errorf("expected type inferred as tcp, got %s", m.Type)
+	}
+
+	if err := m.SetType(""); err != nil || m.Type != TypeTCP {
+		t.Errorf("expected inferred tcp type, got %s (%v)", m.Type, err)
+	}
+
+	m.Update("tcp://missing-port", 5*time.Minute)
+	if err := m.SetType(TypeTCP); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for tcp target without port, got %v", err)
+	}
+
+	udp := NewURLMonitor("udp://game.example.com:27015", 5*time.Minute)
+	if udp.Type != TypeUDP {
+		t.Errorf("expected type inferred as udp, got %s", udp.Type)
+	}
+
+	ws := NewURLMonitor("wss://realtime.example.com/feed", 5*time.Minute)
+	if ws.Type != TypeWebSocket {
+		t.Errorf("expected type inferred as websocket, got %s", ws.Type)
+	}
+	m.Update("https://realtime.example.com/feed", 5*time.Minute)
+	if err := m.SetType(TypeWebSocket); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for websocket type on https target, got %v", err)
+	}
+
+	if err := m.SetType("smtp"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for unknown type, got %v", err)
+	}
+}
+
+func TestURLMonitor_SetTimeouts(t *testing.T) {
+	m := NewURLMonitor("https://example.com", 5*time.Minute)
+	if m.Timeout != DefaultTimeout {
+		t.Errorf("expected default timeout %v, got %v", DefaultTimeout, m.Timeout)
+	}
+
+	if err := m.SetTimeouts(2*time.Second, 500*time.Millisecond); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if m.LatencyStatus(400*time.Millisecond) != StatusUp {
+		t.Error("expected fast response to be up")
+	}
+	if m.LatencyStatus(600*time.Millisecond) != StatusDegraded {
+		t.Error("expected slow response to be degraded")
+	}
+
+	if err := m.SetTimeouts(10*time.Minute, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for too long timeout, got %v", err)
+	}
+	if err := m.SetTimeouts(time.Second, -time.Second); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for negative slow threshold, got %v", err)
+	}
+}
+
+func TestURLMonitor_SetRetryPolicy(t *testing.T) {
+	m := NewURLMonitor("https://example.com", 5*time.Minute)
+
+	if err := m.SetRetryPolicy(3, 5*time.Second); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if m.Retries != 3 || m.RetryDelay != 5*time.Second {
+		t.Errorf("expected retry policy to be set, got %d / %v", m.Retries, m.RetryDelay)
+	}
+
+	if err := m.SetRetryPolicy(-1, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for negative retries, got %v", err)
+	}
+	if err := m.SetRetryPolicy(1, 2*time.Minute); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for long delay, got %v", err)
+	}
+}
+
+func TestURLMonitor_SetRedirectPolicy(t *testing.T) {
+	m := NewURLMonitor("https://example.com", 5*time.Minute)
+	if !m.FollowRedirects || m.MaxRedirects != DefaultMaxRedirects {
+		t.Errorf("expected redirects to be followed by default, got %v / %d", m.FollowRedirects, m.MaxRedirects)
+	}
+
+	if err := m.SetRedirectPolicy(false, 0, "https://example.com/home"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if m.FollowRedirects || m.ExpectedURL != "https://example.com/home" {
+		t.Errorf("expected redirect policy to be set, got %+v", m)
+	}
+
+	if err := m.SetRedirectPolicy(true, 0, "/relative"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for relative URL, got %v", err)
+	}
+	if err := m.SetRedirectPolicy(true, 100, ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for too many redirects, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Watch turns an http monitor into a content watcher. Select narrows the
+// body to a jsonpath ($...) or to the matches of a regular expression (its
+// first group when it has one), Ignore removes volatile parts such as
+// timestamps and Normalize collapses whitespace, all before hashing.
+// LastHash is the hash of the latest snapshot.
+type Watch struct {
+	Select    string
+	Ignore    []string
+	Normalize bool
+	LastHash  string
+}
+
+// Snapshot is the watched content of a monitor at the moment it changed.
+type Snapshot struct {
+	ID         int64
+	MonitorID  string
+	CapturedAt time.Time
+	Hash       string
+	Content    string
+}
+
+func (w Watch) Validate() error {
+	if strings.HasPrefix(w.Select, "$") {
+		if _, err := parseJSONPath(w.Select); err != nil {
+			return err
+		}
+	} else if _, err := regexp.Compile(w.Select); err != nil {
+		return fmt.Errorf("%w: invalid watch selector %q: %v", ErrInvalidConfig, w.Select, err)
+	}
+	for _, pattern := range w.Ignore {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%w: invalid ignore pattern %q: %v", ErrInvalidConfig, pattern, err)
+		}
+	}
+	return nil
+}
+
+// Content returns the part of body that is hashed and stored.
+func (w Watch) Content(body []byte) (string, error) {
+	content, err := w.selectContent(body)
+	if err != nil {
+		return "", err
+	}
+	for _, pattern := range w.Ignore {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		content = re.ReplaceAllString(content, "")
+	}
+	if w.Normalize {
+		content = normalizeWhitespace(content)
+	}
+	return content, nil
+}
+
+func (w Watch) selectContent(body []byte) (string, error) {
+	switch {
+	case w.Select == "":
+		return string(body), nil
+	case strings.HasPrefix(w.Select, "$"):
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return "", fmt.Errorf("body is not valid JSON: %v", err)
+		}
+		value, err := EvalJSONPath(doc, w.Select)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", w.Select, err)
+		}
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		// Indented JSON keeps diffs readable line by line.
+		encoded, err := json.MarshalIndent(value, "", "  ")
+		return string(encoded), err
+	}
+
+	re, err := regexp.Compile(w.Select)
+	if err != nil {
+		return "", err
+	}
+	matches := re.FindAllSubmatch(body, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("body does not match %q", w.Select)
+	}
+	selected := make([]string, len(matches))
+	for i, match := range matches {
+		selected[i] = string(match[0])
+		if len(match) > 1 {
+			selected[i] = string(match[1])
+		}
+	}
+	return strings.Join(selected, "\n"), nil
+}
+
+// normalizeWhitespace collapses runs of blanks within lines and drops
+// empty lines, so reformatting alone does not count as a change.
+func normalizeWhitespace(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines = append(lines, strings.Join(fields, " "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetWatch enables content watching on an http monitor, or disables it
+// when w is nil. The hash of the latest snapshot survives updates.
+func (u *URLMonitor) SetWatch(w *Watch) error {
+	if w == nil {
+		u.Watch = nil
+		return nil
+	}
+	if u.Type != TypeHTTP {
+		return fmt.Errorf("%w: content watching is only supported by http monitors", ErrInvalidConfig)
+	}
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	watch := *w
+	if u.Watch != nil {
+		watch.LastHash = u.Watch.LastHash
+	}
+	u.Watch = &watch
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// ObserveContent hashes the watched part of body and returns a snapshot
+// when it differs from the previous one, or nil when nothing changed.
+func (u *URLMonitor) ObserveContent(body []byte, at time.Time) (*Snapshot, error) {
+	content, err := u.Watch.Content(body)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	if hash == u.Watch.LastHash {
+		return nil, nil
+	}
+	u.Watch.LastHash = hash
+
+	return &Snapshot{
+		MonitorID:  u.ID,
+		CapturedAt: at,
+		Hash:       hash,
+		Content:    content,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWatch_Content(t *testing.T) {
+	tests := []struct {
+		name  string
+		watch Watch
+		body  string
+		want  string
+	}{
+		{"whole body", Watch{}, "<h1>Status</h1>", "<h1>Status</h1>"},
+		{"jsonpath string", Watch{Select: "$.version"}, `{"version":"1.4.2","ts":1}`, "1.4.2"},
+		{"jsonpath object", Watch{Select: "$.info"}, `{"info":{"a":1}}`, "{\n  \"a\": 1\n}"},
+		{"regex group", Watch{Select: `<main>(.*?)</main>`}, "<nav>x</nav><main>hello</main>", "hello"},
+		{"regex all matches", Watch{Select: `h\d`}, "h1 h2", "h1\nh2"},
+		{"ignore", Watch{Ignore: []string{`\d{2}:\d{2}`}}, "updated 12:30", "updated "},
+		{"normalize", Watch{Normalize: true}, "  a   b \n\n\tc\n", "a b\nc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.watch.Content([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := (Watch{Select: "$.missing"}).Content([]byte(`{}`)); err == nil {
+		t.Error("expected error when the selector finds nothing")
+	}
+}
+
+func TestURLMonitor_SetWatch(t *testing.T) {
+	m := NewURLMonitor("https://example.com", time.Minute)
+
+	if err := m.SetWatch(&Watch{Select: "("}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for invalid regex, got %v", err)
+	}
+	if err := m.SetWatch(&Watch{Ignore: []string{"["}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for invalid ignore pattern, got %v", err)
+	}
+
+	m.SetWatch(&Watch{})
+	m.Watch.LastHash = "abc"
+	m.SetWatch(&Watch{Normalize: true})
+	if m.Watch.LastHash != "abc" || !m.Watch.Normalize {
+		t.Errorf("expected hash to survive updates, got %+v", m.Watch)
+	}
+
+	tcp := NewURLMonitor("tcp://example.com:25", time.Minute)
+	if err := tcp.SetWatch(&Watch{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for tcp monitor, got %v", err)
+	}
+}
+
+func TestURLMonitor_ObserveContent(t *testing.T) {
+	m := NewURLMonitor("https://example.com", time.Minute)
+	m.SetWatch(&Watch{Ignore: []string{`nonce=\w+`}})
+	now := time.Now()
+
+	first, err := m.ObserveContent([]byte("hello nonce=a1"), now)
+	if err != nil || first == nil {
+		t.Fatalf("expected a baseline snapshot, got %v (%v)", first, err)
+	}
+	if first.Content != "hello " || first.MonitorID != m.ID || len(first.Hash) != 64 {
+		t.Errorf("unexpected snapshot %+v", first)
+	}
+
+	// Изменился только игнорируемый фрагмент
+	if same, _ := m.ObserveContent([]byte("hello nonce=b2"), now); same != nil {
+		t.Errorf("expected no snapshot for unchanged content, got %+v", same)
+	}
+
+	changed, _ := m.ObserveContent([]byte("defaced nonce=c3"), now)
+	if changed == nil || changed.Hash == first.Hash || m.Watch.LastHash != changed.Hash {
+		t.Errorf("expected a new snapshot, got %+v", changed)
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds process-wide settings read from the environment.
+type Config struct {
+	// DefaultProxy is used by http monitors without their own proxy.
+	DefaultProxy string
+	// Workers, PerHostLimit and QueueSize bound concurrent checks; zero
+	// keeps the checker's defaults.
+	Workers      int
+	PerHostLimit int
+	QueueSize    int
+	// Jitter bounds the random delay added to each scheduled check.
+	Jitter time.Duration
+	// MinInterval and MaxInterval bound monitor intervals; zero keeps the
+	// service's defaults.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// Instance names this process among checkers sharing the database.
+	Instance string
+	// LeaseTTL enables coordination through monitor leases when non-zero.
+	LeaseTTL time.Duration
+}
+
+func Load() (*Config, error) {
+	cfg := &Config{
+		DefaultProxy: os.Getenv("URLCHECKER_PROXY"),
+		Instance:     os.Getenv("URLCHECKER_INSTANCE"),
+	}
+	if cfg.Instance == "" {
+		hostname, _ := os.Hostname()
+		cfg.Instance = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	ints := []struct {
+		name  string
+		value *int
+	}{
+		{"URLCHECKER_WORKERS", &cfg.Workers},
+		{"URLCHECKER_PER_HOST_LIMIT", &cfg.PerHostLimit},
+		{"URLCHECKER_QUEUE_SIZE", &cfg.QueueSize},
+	}
+	for _, setting := range ints {
+		raw := os.Getenv(setting.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s must be a non-negative integer, got %q", setting.name, raw)
+		}
+		*setting.value = n
+	}
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"URLCHECKER_JITTER", &cfg.Jitter},
+		{"URLCHECKER_MIN_INTERVAL", &cfg.MinInterval},
+		{"URLCHECKER_MAX_INTERVAL", &cfg.MaxInterval},
+		{"URLCHECKER_LEASE_TTL", &cfg.LeaseTTL},
+	}
+	for _, setting := range durations {
+		raw := os.Getenv(setting.name)
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s must be a non-negative duration, got %q", setting.name, raw)
+		}
+		*setting.value = d
+	}
+
+	return cfg, nil
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"expvar"
 	"net/http"
 	"urlChecker/internal/interface/api"
 )
@@ -19,9 +20,22 @@ func NewRouter(handler *api.Handler) *http.ServeMux {
 	})
 
 	mux.HandleFunc("GET /monitors/{id}", handler.GetMonitor)
+	mux.HandleFunc("GET /monitors/{id}/results", handler.GetResults)
+	mux.HandleFunc("GET /monitors/{id}/snapshots", handler.GetSnapshots)
+	mux.HandleFunc("GET /monitors/{id}/snapshots/diff", handler.DiffSnapshots)
+	mux.HandleFunc("GET /monitors/{id}/snapshots/{snapshot}", handler.GetSnapshot)
 	mux.HandleFunc("PUT /monitors/{id}", handler.UpdateMonitor)
 	mux.HandleFunc("DELETE /monitors/{id}", handler.DeleteMonitor)
 	mux.HandleFunc("POST /monitors/{id}/resume", handler.ResumeMonitor)
 	mux.HandleFunc("POST /monitors/{id}/pause", handler.PauseMonitor)
+	mux.HandleFunc("POST /maintenance", handler.CreateMaintenance)
+	mux.HandleFunc("GET /maintenance", handler.GetAllMaintenance)
+	mux.HandleFunc("GET /maintenance/{id}", handler.GetMaintenance)
+	mux.HandleFunc("PUT /maintenance/{id}", handler.UpdateMaintenance)
+	mux.HandleFunc("DELETE /maintenance/{id}", handler.DeleteMaintenance)
+	mux.HandleFunc("POST /heartbeat/{token}", handler.Heartbeat)
+	mux.HandleFunc("POST /heartbeat/{token}/{kind}", handler.Heartbeat)
+	// Runtime metrics, including the checker's queue depth.
+	mux.Handle("GET /debug/vars", expvar.Handler())
 	return mux
 }
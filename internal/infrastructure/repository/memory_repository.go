@@ -1,19 +1,35 @@
 package repository
 
 import (
-	"errors"
+	"fmt"
 	"sync"
+	"time"
 	"urlChecker/internal/domain/monitor"
 )
 
 type MemoryRepository struct {
-	mu      sync.RWMutex
-	storage map[string]*monitor.URLMonitor
+	mu           sync.RWMutex
+	storage      map[string]*monitor.URLMonitor
+	results      map[string][]*monitor.CheckResult
+	nextResultID int64
+	snapshots    map[string][]*monitor.Snapshot
+	nextSnapshot int64
+	windows      map[string]*monitor.MaintenanceWindow
+	leases       map[string]lease
+}
+
+type lease struct {
+	owner     string
+	expiresAt time.Time
 }
 
 func NewMemoryRepository() *MemoryRepository {
 	return &MemoryRepository{
-		storage: make(map[string]*monitor.URLMonitor),
+		storage:   make(map[string]*monitor.URLMonitor),
+		results:   make(map[string][]*monitor.CheckResult),
+		snapshots: make(map[string][]*monitor.Snapshot),
+		windows:   make(map[string]*monitor.MaintenanceWindow),
+		leases:    make(map[string]lease),
 	}
 }
 
@@ -29,7 +45,7 @@ func (r *MemoryRepository) FindByID(id string) (*monitor.URLMonitor, error) {
 	defer r.mu.RUnlock()
 	m, exists := r.storage[id]
 	if !exists {
-		return nil, errors.New("monitor not found")
+		return nil, fmt.Errorf("monitor %w", monitor.ErrNotFound)
 	}
 	return m, nil
 }
@@ -44,10 +60,36 @@ func (r *MemoryRepository) FindAll() ([]*monitor.URLMonitor, error) {
 	return result, nil
 }
 
+func (r *MemoryRepository) FindUpdatedSince(t time.Time) ([]*monitor.URLMonitor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*monitor.URLMonitor, 0)
+	for _, m := range r.storage {
+		if !m.UpdatedAt.Before(t) {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) FindByHeartbeatToken(token string) (*monitor.URLMonitor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, m := range r.storage {
+		if m.Heartbeat != nil && m.Heartbeat.Token == token {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("monitor %w", monitor.ErrNotFound)
+}
+
 func (r *MemoryRepository) Delete(id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.storage, id)
+	delete(r.results, id)
+	delete(r.snapshots, id)
+	delete(r.leases, id)
 	return nil
 }
 
@@ -57,3 +99,154 @@ func (r *MemoryRepository) Update(m *monitor.URLMonitor) error {
 	r.storage[m.ID] = m
 	return nil
 }
+
+func (r *MemoryRepository) UpdateCheckState(m *monitor.URLMonitor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, exists := r.storage[m.ID]
+	if !exists || stored == m {
+		return nil
+	}
+	stored.LastChecked = m.LastChecked
+	stored.LastStatus = m.LastStatus
+	stored.Certificate = m.Certificate
+	if stored.Watch != nil && m.Watch != nil {
+		stored.Watch.LastHash = m.Watch.LastHash
+	}
+	return nil
+}
+
+func (r *MemoryRepository) UpdateHeartbeat(m *monitor.URLMonitor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, exists := r.storage[m.ID]
+	if !exists || stored == m {
+		return nil
+	}
+	stored.Heartbeat = m.Heartbeat
+	stored.LastChecked = m.LastChecked
+	stored.LastStatus = m.LastStatus
+	return nil
+}
+
+func (r *MemoryRepository) SaveResult(result *monitor.CheckResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextResultID++
+	result.ID = r.nextResultID
+	r.results[result.MonitorID] = append(r.results[result.MonitorID], result)
+	return nil
+}
+
+func (r *MemoryRepository) FindResults(monitorID string, from, to time.Time) ([]*monitor.CheckResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*monitor.CheckResult, 0)
+	for _, res := range r.results[monitorID] {
+		if !from.IsZero() && res.CheckedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && res.CheckedAt.After(to) {
+			continue
+		}
+		result = append(result, res)
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) SaveSnapshot(snapshot *monitor.Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextSnapshot++
+	snapshot.ID = r.nextSnapshot
+	r.snapshots[snapshot.MonitorID] = append(r.snapshots[snapshot.MonitorID], snapshot)
+	return nil
+}
+
+func (r *MemoryRepository) FindSnapshots(monitorID string) ([]*monitor.Snapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*monitor.Snapshot{}, r.snapshots[monitorID]...), nil
+}
+
+func (r *MemoryRepository) FindSnapshot(monitorID string, id int64) (*monitor.Snapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, snapshot := range r.snapshots[monitorID] {
+		if snapshot.ID == id {
+			return snapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("snapshot %w", monitor.ErrNotFound)
+}
+
+func (r *MemoryRepository) SaveWindow(window *monitor.MaintenanceWindow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.windows[window.ID] = window
+	return nil
+}
+
+func (r *MemoryRepository) UpdateWindow(window *monitor.MaintenanceWindow) error {
+	return r.SaveWindow(window)
+}
+
+func (r *MemoryRepository) FindWindow(id string) (*monitor.MaintenanceWindow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	window, exists := r.windows[id]
+	if !exists {
+		return nil, fmt.Errorf("maintenance window %w", monitor.ErrNotFound)
+	}
+	return window, nil
+}
+
+func (r *MemoryRepository) FindWindows() ([]*monitor.MaintenanceWindow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*monitor.MaintenanceWindow, 0, len(r.windows))
+	for _, window := range r.windows {
+		result = append(result, window)
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) DeleteWindow(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.windows, id)
+	return nil
+}
+
+func (r *MemoryRepository) AcquireLease(monitorID, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, exists := r.leases[monitorID]
+	if exists && current.owner != owner && current.expiresAt.After(now) {
+		return false, nil
+	}
+	r.leases[monitorID] = lease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (r *MemoryRepository) RenewLeases(owner string, now time.Time, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, current := range r.leases {
+		if current.owner == owner {
+			r.leases[id] = lease{owner: owner, expiresAt: now.Add(ttl)}
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) ReleaseLeases(owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, current := range r.leases {
+		if current.owner == owner {
+			delete(r.leases, id)
+		}
+	}
+	return nil
+}
@@ -93,3 +93,28 @@ func TestMemoryRepository_Update(t *testing.T) {
 		t.Errorf("expected URL to be updated to https://updated.com, got %s", updated.URL)
 	}
 }
+
+func TestMemoryRepository_FindResults(t *testing.T) {
+	repo := NewMemoryRepository()
+	now := time.Now()
+
+	old := monitor.NewCheckResult("m1", now.Add(-2*time.Hour))
+	recent := monitor.NewCheckResult("m1", now)
+	other := monitor.NewCheckResult("m2", now)
+	repo.SaveResult(old)
+	repo.SaveResult(recent)
+	repo.SaveResult(other)
+
+	all, err := repo.FindResults("m1", time.Time{}, time.Time{})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("expected 2 results, got %d", len(all))
+	}
+
+	filtered, _ := repo.FindResults("m1", now.Add(-time.Hour), time.Time{})
+	if len(filtered) != 1 || filtered[0].ID != recent.ID {
+		t.Errorf("expected only the recent result, got %v", filtered)
+	}
+}
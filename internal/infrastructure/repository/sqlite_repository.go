@@ -2,7 +2,9 @@ package repository
 
 import (
 	"database/sql"
-	"errors"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 	"urlChecker/internal/domain/monitor"
 
@@ -13,8 +15,68 @@ type SQLiteRepository struct {
 	db *sql.DB
 }
 
+const monitorColumns = `id, type, url, interval_seconds, method, headers, body, auth, tls, proxy, expected_status, assertions, cert_expiry_days, certificate, dns, steps, heartbeat, heartbeat_token, watch, cron, tags, timeout_ms, slow_threshold_ms, retries, retry_delay_ms, follow_redirects, max_redirects, expected_url, is_active, last_checked, last_status, created_at, updated_at`
+
+var schemaMigrations = []struct {
+	table      string
+	name       string
+	definition string
+}{
+	{"monitors", "method", `TEXT NOT NULL DEFAULT 'GET'`},
+	{"monitors", "headers", `TEXT NOT NULL DEFAULT '{}'`},
+	{"monitors", "body", `TEXT NOT NULL DEFAULT ''`},
+	{"monitors", "expected_status", `TEXT NOT NULL DEFAULT '200-399'`},
+	{"monitors", "last_status", `TEXT NOT NULL DEFAULT ''`},
+	{"monitors", "assertions", `TEXT NOT NULL DEFAULT '[]'`},
+	{"monitors", "cert_expiry_days", `INTEGER NOT NULL DEFAULT 14`},
+	{"monitors", "certificate", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "type", `TEXT NOT NULL DEFAULT 'http'`},
+	{"monitors", "dns", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "timeout_ms", `INTEGER NOT NULL DEFAULT 10000`},
+	{"monitors", "slow_threshold_ms", `INTEGER NOT NULL DEFAULT 0`},
+	{"monitors", "retries", `INTEGER NOT NULL DEFAULT 0`},
+	{"monitors", "retry_delay_ms", `INTEGER NOT NULL DEFAULT 0`},
+	{"monitors", "follow_redirects", `INTEGER NOT NULL DEFAULT 1`},
+	{"monitors", "max_redirects", `INTEGER NOT NULL DEFAULT 10`},
+	{"monitors", "expected_url", `TEXT NOT NULL DEFAULT ''`},
+	{"monitors", "auth", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "tls", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "proxy", `TEXT NOT NULL DEFAULT ''`},
+	{"monitors", "steps", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "heartbeat", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "heartbeat_token", `TEXT NOT NULL DEFAULT ''`},
+	{"monitors", "watch", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "cron", `TEXT NOT NULL DEFAULT 'null'`},
+	{"monitors", "tags", `TEXT NOT NULL DEFAULT 'null'`},
+	{"check_results", "status", `TEXT NOT NULL DEFAULT ''`},
+	{"check_results", "failed_assertion", `TEXT NOT NULL DEFAULT ''`},
+	{"check_results", "timings", `TEXT NOT NULL DEFAULT 'null'`},
+	{"check_results", "attempts", `INTEGER NOT NULL DEFAULT 1`},
+	{"check_results", "attempt_errors", `TEXT NOT NULL DEFAULT 'null'`},
+	{"check_results", "redirect_chain", `TEXT NOT NULL DEFAULT 'null'`},
+	{"check_results", "error_kind", `TEXT NOT NULL DEFAULT ''`},
+	{"check_results", "steps", `TEXT NOT NULL DEFAULT 'null'`},
+	{"check_results", "log", `TEXT NOT NULL DEFAULT ''`},
+	{"check_results", "content_changed", `INTEGER NOT NULL DEFAULT 0`},
+	{"check_results", "in_maintenance", `INTEGER NOT NULL DEFAULT 0`},
+}
+
+// schemaIndexes are created after migrations since they may cover
+// migrated columns.
+var schemaIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_monitors_heartbeat_token ON monitors (heartbeat_token)`,
+	`CREATE INDEX IF NOT EXISTS idx_monitors_updated_at ON monitors (updated_at)`,
+}
+
+// NewSQLiteRepository opens the database at dbPath. Several repositories,
+// in one process or many, may share the file; writers wait for each other
+// instead of failing with "database is locked".
 func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	db, err := sql.Open("sqlite3", dbPath+separator+"_busy_timeout=5000")
 	if err != nil {
 		return nil, err
 	}
@@ -27,6 +89,9 @@ func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
 	if err := repo.createTable(); err != nil {
 		return nil, err
 	}
+	if err := repo.migrate(); err != nil {
+		return nil, err
+	}
 
 	return repo, nil
 }
@@ -41,75 +106,144 @@ func (r *SQLiteRepository) createTable() error {
 		last_checked INTEGER,
 		created_at INTEGER NOT NULL,
 		updated_at INTEGER NOT NULL
-	)`
+	);
+
+	CREATE TABLE IF NOT EXISTS check_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		monitor_id TEXT NOT NULL,
+		checked_at INTEGER NOT NULL,
+		status_code INTEGER NOT NULL,
+		response_time_ns INTEGER NOT NULL,
+		error TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_check_results_monitor ON check_results (monitor_id, checked_at);
+
+	CREATE TABLE IF NOT EXISTS snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		monitor_id TEXT NOT NULL,
+		captured_at INTEGER NOT NULL,
+		hash TEXT NOT NULL,
+		content TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_snapshots_monitor ON snapshots (monitor_id, id);
+
+	CREATE TABLE IF NOT EXISTS maintenance_windows (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		mode TEXT NOT NULL,
+		monitor_ids TEXT NOT NULL,
+		tags TEXT NOT NULL,
+		start_at INTEGER NOT NULL,
+		end_at INTEGER NOT NULL,
+		recurrence TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS leases (
+		monitor_id TEXT PRIMARY KEY,
+		owner TEXT NOT NULL,
+		expires_at_ms INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_leases_owner ON leases (owner)`
 
 	_, err := r.db.Exec(query)
 	return err
 }
 
-func (r *SQLiteRepository) Save(m *monitor.URLMonitor) error {
-	query := `
-	INSERT INTO monitors (id, url, interval_seconds, is_active, last_checked, created_at, updated_at)
-	VALUES (?, ?, ?, ?, ?, ?, ?)`
+// migrate adds columns introduced after the initial schema so databases
+// created by older versions keep working.
+func (r *SQLiteRepository) migrate() error {
+	existing := make(map[string]map[string]bool)
+
+	for _, column := range schemaMigrations {
+		if existing[column.table] == nil {
+			columns, err := r.tableColumns(column.table)
+			if err != nil {
+				return err
+			}
+			existing[column.table] = columns
+		}
+		if existing[column.table][column.name] {
+			continue
+		}
 
-	var lastChecked *int64
-	if m.LastChecked != nil {
-		ts := m.LastChecked.Unix()
-		lastChecked = &ts
+		query := `ALTER TABLE ` + column.table + ` ADD COLUMN ` + column.name + ` ` + column.definition
+		if _, err := r.db.Exec(query); err != nil {
+			return err
+		}
 	}
 
-	_, err := r.db.Exec(query,
-		m.ID,
-		m.URL,
-		int64(m.Interval.Seconds()),
-		boolToInt(m.IsActive),
-		lastChecked,
-		m.CreatedAt.Unix(),
-		m.UpdatedAt.Unix(),
-	)
+	for _, query := range schemaIndexes {
+		if _, err := r.db.Exec(query); err != nil {
+			return err
+		}
+	}
 
-	return err
+	return nil
 }
 
-func (r *SQLiteRepository) FindByID(id string) (*monitor.URLMonitor, error) {
-	query := `
-	SELECT id, url, interval_seconds, is_active, last_checked, created_at, updated_at
-	FROM monitors WHERE id = ?`
+func (r *SQLiteRepository) tableColumns(table string) (map[string]bool, error) {
+	rows, err := r.db.Query(`PRAGMA table_info(` + table + `)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	row := r.db.QueryRow(query, id)
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
 
-	var m monitor.URLMonitor
-	var intervalSeconds int64
-	var isActive int
-	var lastChecked *int64
-	var createdAt, updatedAt int64
+	return columns, rows.Err()
+}
 
-	err := row.Scan(&m.ID, &m.URL, &intervalSeconds, &isActive, &lastChecked, &createdAt, &updatedAt)
+func (r *SQLiteRepository) Save(m *monitor.URLMonitor) error {
+	query := `INSERT INTO monitors (` + monitorColumns + `) VALUES (` + placeholders(monitorColumns) + `)`
+
+	values, err := monitorValues(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, values...)
+	return err
+}
+
+func (r *SQLiteRepository) FindByID(id string) (*monitor.URLMonitor, error) {
+	query := `SELECT ` + monitorColumns + ` FROM monitors WHERE id = ?`
+
+	m, err := scanMonitor(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
-		return nil, errors.New("monitor not found")
+		return nil, fmt.Errorf("monitor %w", monitor.ErrNotFound)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	m.Interval = time.Duration(intervalSeconds) * time.Second
-	m.IsActive = intToBool(isActive)
-	if lastChecked != nil {
-		t := time.Unix(*lastChecked, 0)
-		m.LastChecked = &t
-	}
-	m.CreatedAt = time.Unix(createdAt, 0)
-	m.UpdatedAt = time.Unix(updatedAt, 0)
-
-	return &m, nil
+	return m, nil
 }
 
 func (r *SQLiteRepository) FindAll() ([]*monitor.URLMonitor, error) {
-	query := `
-	SELECT id, url, interval_seconds, is_active, last_checked, created_at, updated_at
-	FROM monitors`
+	return r.findMonitors(`SELECT ` + monitorColumns + ` FROM monitors`)
+}
 
-	rows, err := r.db.Query(query)
+func (r *SQLiteRepository) FindUpdatedSince(t time.Time) ([]*monitor.URLMonitor, error) {
+	return r.findMonitors(`SELECT `+monitorColumns+` FROM monitors WHERE updated_at >= ?`, t.Unix())
+}
+
+func (r *SQLiteRepository) findMonitors(query string, args ...any) ([]*monitor.URLMonitor, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -118,59 +252,408 @@ func (r *SQLiteRepository) FindAll() ([]*monitor.URLMonitor, error) {
 	var monitors []*monitor.URLMonitor
 
 	for rows.Next() {
-		var m monitor.URLMonitor
-		var intervalSeconds int64
-		var isActive int
-		var lastChecked *int64
-		var createdAt, updatedAt int64
-
-		err := rows.Scan(&m.ID, &m.URL, &intervalSeconds, &isActive, &lastChecked, &createdAt, &updatedAt)
+		m, err := scanMonitor(rows)
 		if err != nil {
 			return nil, err
 		}
+		monitors = append(monitors, m)
+	}
 
-		m.Interval = time.Duration(intervalSeconds) * time.Second
-		m.IsActive = intToBool(isActive)
-		if lastChecked != nil {
-			t := time.Unix(*lastChecked, 0)
-			m.LastChecked = &t
-		}
-		m.CreatedAt = time.Unix(createdAt, 0)
-		m.UpdatedAt = time.Unix(updatedAt, 0)
+	return monitors, rows.Err()
+}
+
+func (r *SQLiteRepository) FindByHeartbeatToken(token string) (*monitor.URLMonitor, error) {
+	query := `SELECT ` + monitorColumns + ` FROM monitors WHERE heartbeat_token = ? AND heartbeat_token != ''`
 
-		monitors = append(monitors, &m)
+	m, err := scanMonitor(r.db.QueryRow(query, token))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("monitor %w", monitor.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return monitors, rows.Err()
+	return m, nil
 }
 
 func (r *SQLiteRepository) Delete(id string) error {
 	query := `DELETE FROM monitors WHERE id = ?`
-	_, err := r.db.Exec(query, id)
+	if _, err := r.db.Exec(query, id); err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM check_results WHERE monitor_id = ?`, id); err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM snapshots WHERE monitor_id = ?`, id); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(`DELETE FROM leases WHERE monitor_id = ?`, id)
 	return err
 }
 
 func (r *SQLiteRepository) Update(m *monitor.URLMonitor) error {
+	query := `UPDATE monitors SET (` + monitorColumns + `) = (` + placeholders(monitorColumns) + `) WHERE id = ?`
+
+	values, err := monitorValues(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, append(values, m.ID)...)
+	return err
+}
+
+func (r *SQLiteRepository) UpdateCheckState(m *monitor.URLMonitor) error {
 	query := `
-	UPDATE monitors
-	SET url = ?, interval_seconds = ?, is_active = ?, last_checked = ?, updated_at = ?
+	UPDATE monitors SET last_checked = ?, last_status = ?, certificate = ?,
+		watch = CASE WHEN watch = 'null' OR ? IS NULL THEN watch ELSE json_set(watch, '$.LastHash', ?) END
 	WHERE id = ?`
 
-	var lastChecked *int64
-	if m.LastChecked != nil {
-		ts := m.LastChecked.Unix()
-		lastChecked = &ts
+	certificate, err := json.Marshal(m.Certificate)
+	if err != nil {
+		return err
+	}
+	var lastHash *string
+	if m.Watch != nil {
+		lastHash = &m.Watch.LastHash
 	}
 
-	_, err := r.db.Exec(query,
-		m.URL,
-		int64(m.Interval.Seconds()),
-		boolToInt(m.IsActive),
-		lastChecked,
-		m.UpdatedAt.Unix(),
-		m.ID,
+	_, err = r.db.Exec(query, unixOrNil(m.LastChecked), string(m.LastStatus), string(certificate), lastHash, lastHash, m.ID)
+	return err
+}
+
+func (r *SQLiteRepository) UpdateHeartbeat(m *monitor.URLMonitor) error {
+	query := `UPDATE monitors SET heartbeat = ?, last_checked = ?, last_status = ? WHERE id = ?`
+
+	heartbeat, err := json.Marshal(m.Heartbeat)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, string(heartbeat), unixOrNil(m.LastChecked), string(m.LastStatus), m.ID)
+	return err
+}
+
+func (r *SQLiteRepository) SaveResult(result *monitor.CheckResult) error {
+	query := `
+	INSERT INTO check_results (monitor_id, checked_at, status, status_code, response_time_ns, timings, error,
+		error_kind, failed_assertion, attempts, attempt_errors, redirect_chain, steps, log, content_changed, in_maintenance)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	timings, err := json.Marshal(result.Timings)
+	if err != nil {
+		return err
+	}
+	attemptErrors, err := json.Marshal(result.AttemptErrors)
+	if err != nil {
+		return err
+	}
+	redirectChain, err := json.Marshal(result.RedirectChain)
+	if err != nil {
+		return err
+	}
+	steps, err := json.Marshal(result.Steps)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.Exec(query,
+		result.MonitorID,
+		result.CheckedAt.Unix(),
+		string(result.Status),
+		result.StatusCode,
+		int64(result.ResponseTime),
+		string(timings),
+		result.Error,
+		string(result.ErrorKind),
+		result.FailedAssertion,
+		result.Attempts,
+		string(attemptErrors),
+		string(redirectChain),
+		string(steps),
+		result.Log,
+		boolToInt(result.ContentChanged),
+		boolToInt(result.InMaintenance),
 	)
+	if err != nil {
+		return err
+	}
+
+	result.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteRepository) FindResults(monitorID string, from, to time.Time) ([]*monitor.CheckResult, error) {
+	query := `
+	SELECT id, monitor_id, checked_at, status, status_code, response_time_ns, timings, error, error_kind,
+		failed_assertion, attempts, attempt_errors, redirect_chain, steps, log, content_changed, in_maintenance
+	FROM check_results WHERE monitor_id = ?`
+	args := []any{monitorID}
+
+	if !from.IsZero() {
+		query += ` AND checked_at >= ?`
+		args = append(args, from.Unix())
+	}
+	if !to.IsZero() {
+		query += ` AND checked_at <= ?`
+		args = append(args, to.Unix())
+	}
+	query += ` ORDER BY checked_at, id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*monitor.CheckResult, 0)
+
+	for rows.Next() {
+		var res monitor.CheckResult
+		var checkedAt, responseTime int64
+		var contentChanged, inMaintenance int
+		var status, errorKind, timings, attemptErrors, redirectChain, steps string
+
+		err := rows.Scan(&res.ID, &res.MonitorID, &checkedAt, &status, &res.StatusCode, &responseTime, &timings,
+			&res.Error, &errorKind, &res.FailedAssertion, &res.Attempts, &attemptErrors, &redirectChain, &steps, &res.Log, &contentChanged, &inMaintenance)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(timings), &res.Timings); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(attemptErrors), &res.AttemptErrors); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(redirectChain), &res.RedirectChain); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(steps), &res.Steps); err != nil {
+			return nil, err
+		}
+
+		res.CheckedAt = time.Unix(checkedAt, 0)
+		res.Status = monitor.Status(status)
+		res.ErrorKind = monitor.ErrorKind(errorKind)
+		res.ResponseTime = time.Duration(responseTime)
+		res.ContentChanged = intToBool(contentChanged)
+		res.InMaintenance = intToBool(inMaintenance)
+
+		results = append(results, &res)
+	}
+
+	return results, rows.Err()
+}
+
+func (r *SQLiteRepository) SaveSnapshot(snapshot *monitor.Snapshot) error {
+	query := `INSERT INTO snapshots (monitor_id, captured_at, hash, content) VALUES (?, ?, ?, ?)`
+
+	res, err := r.db.Exec(query, snapshot.MonitorID, snapshot.CapturedAt.Unix(), snapshot.Hash, snapshot.Content)
+	if err != nil {
+		return err
+	}
+
+	snapshot.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteRepository) FindSnapshots(monitorID string) ([]*monitor.Snapshot, error) {
+	query := `SELECT id, monitor_id, captured_at, hash, content FROM snapshots WHERE monitor_id = ? ORDER BY id`
+
+	rows, err := r.db.Query(query, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := make([]*monitor.Snapshot, 0)
+	for rows.Next() {
+		snapshot, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+func (r *SQLiteRepository) FindSnapshot(monitorID string, id int64) (*monitor.Snapshot, error) {
+	query := `SELECT id, monitor_id, captured_at, hash, content FROM snapshots WHERE monitor_id = ? AND id = ?`
+
+	snapshot, err := scanSnapshot(r.db.QueryRow(query, monitorID, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("snapshot %w", monitor.ErrNotFound)
+	}
+	return snapshot, err
+}
 
+func scanSnapshot(row rowScanner) (*monitor.Snapshot, error) {
+	var snapshot monitor.Snapshot
+	var capturedAt int64
+	if err := row.Scan(&snapshot.ID, &snapshot.MonitorID, &capturedAt, &snapshot.Hash, &snapshot.Content); err != nil {
+		return nil, err
+	}
+	snapshot.CapturedAt = time.Unix(capturedAt, 0)
+	return &snapshot, nil
+}
+
+const windowColumns = `id, name, mode, monitor_ids, tags, start_at, end_at, recurrence, duration_ms, created_at, updated_at`
+
+func (r *SQLiteRepository) SaveWindow(window *monitor.MaintenanceWindow) error {
+	query := `INSERT INTO maintenance_windows (` + windowColumns + `) VALUES (` + placeholders(windowColumns) + `)`
+
+	values, err := windowValues(window)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, values...)
+	return err
+}
+
+func (r *SQLiteRepository) UpdateWindow(window *monitor.MaintenanceWindow) error {
+	query := `UPDATE maintenance_windows SET (` + windowColumns + `) = (` + placeholders(windowColumns) + `) WHERE id = ?`
+
+	values, err := windowValues(window)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, append(values, window.ID)...)
+	return err
+}
+
+func (r *SQLiteRepository) FindWindow(id string) (*monitor.MaintenanceWindow, error) {
+	query := `SELECT ` + windowColumns + ` FROM maintenance_windows WHERE id = ?`
+
+	window, err := scanWindow(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("maintenance window %w", monitor.ErrNotFound)
+	}
+	return window, err
+}
+
+func (r *SQLiteRepository) FindWindows() ([]*monitor.MaintenanceWindow, error) {
+	query := `SELECT ` + windowColumns + ` FROM maintenance_windows ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := make([]*monitor.MaintenanceWindow, 0)
+	for rows.Next() {
+		window, err := scanWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, rows.Err()
+}
+
+func (r *SQLiteRepository) DeleteWindow(id string) error {
+	_, err := r.db.Exec(`DELETE FROM maintenance_windows WHERE id = ?`, id)
+	return err
+}
+
+// windowValues returns the window's fields in windowColumns order. One-off
+// times are stored in seconds like other timestamps; zero marks a
+// recurring window.
+func windowValues(w *monitor.MaintenanceWindow) ([]any, error) {
+	monitorIDs, err := json.Marshal(w.MonitorIDs)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := json.Marshal(w.Tags)
+	if err != nil {
+		return nil, err
+	}
+	recurrence, err := json.Marshal(w.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		w.ID,
+		w.Name,
+		string(w.Mode),
+		string(monitorIDs),
+		string(tags),
+		unixOrZero(w.Start),
+		unixOrZero(w.End),
+		string(recurrence),
+		w.Duration.Milliseconds(),
+		w.CreatedAt.Unix(),
+		w.UpdatedAt.Unix(),
+	}, nil
+}
+
+func scanWindow(row rowScanner) (*monitor.MaintenanceWindow, error) {
+	var w monitor.MaintenanceWindow
+	var mode, monitorIDs, tags, recurrence string
+	var startAt, endAt, durationMs, createdAt, updatedAt int64
+
+	err := row.Scan(&w.ID, &w.Name, &mode, &monitorIDs, &tags, &startAt, &endAt, &recurrence, &durationMs, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(monitorIDs), &w.MonitorIDs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &w.Tags); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(recurrence), &w.Recurrence); err != nil {
+		return nil, err
+	}
+
+	w.Mode = monitor.MaintenanceMode(mode)
+	if startAt != 0 {
+		w.Start = time.Unix(startAt, 0)
+	}
+	if endAt != 0 {
+		w.End = time.Unix(endAt, 0)
+	}
+	w.Duration = time.Duration(durationMs) * time.Millisecond
+	w.CreatedAt = time.Unix(createdAt, 0)
+	w.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return &w, nil
+}
+
+// AcquireLease relies on a single upsert so that concurrent instances
+// cannot both win: the update only applies to the owner's own lease or an
+// expired one.
+func (r *SQLiteRepository) AcquireLease(monitorID, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	query := `
+	INSERT INTO leases (monitor_id, owner, expires_at_ms) VALUES (?, ?, ?)
+	ON CONFLICT (monitor_id) DO UPDATE SET owner = excluded.owner, expires_at_ms = excluded.expires_at_ms
+	WHERE leases.owner = excluded.owner OR leases.expires_at_ms <= ?`
+
+	res, err := r.db.Exec(query, monitorID, owner, now.Add(ttl).UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *SQLiteRepository) RenewLeases(owner string, now time.Time, ttl time.Duration) error {
+	_, err := r.db.Exec(`UPDATE leases SET expires_at_ms = ? WHERE owner = ?`, now.Add(ttl).UnixMilli(), owner)
+	return err
+}
+
+func (r *SQLiteRepository) ReleaseLeases(owner string) error {
+	_, err := r.db.Exec(`DELETE FROM leases WHERE owner = ?`, owner)
 	return err
 }
 
@@ -188,3 +671,191 @@ func boolToInt(b bool) int {
 func intToBool(i int) bool {
 	return i != 0
 }
+
+func placeholders(columns string) string {
+	n := strings.Count(columns, ",") + 1
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// monitorValues returns the monitor's fields in monitorColumns order.
+func monitorValues(m *monitor.URLMonitor) ([]any, error) {
+	headers, err := json.Marshal(m.Headers)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := json.Marshal(m.Auth)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := json.Marshal(m.TLS)
+	if err != nil {
+		return nil, err
+	}
+	assertions, err := json.Marshal(m.Assertions)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := json.Marshal(m.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	dns, err := json.Marshal(m.DNS)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := json.Marshal(m.Steps)
+	if err != nil {
+		return nil, err
+	}
+	heartbeat, err := json.Marshal(m.Heartbeat)
+	if err != nil {
+		return nil, err
+	}
+	watch, err := json.Marshal(m.Watch)
+	if err != nil {
+		return nil, err
+	}
+	cron, err := json.Marshal(m.Cron)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := json.Marshal(m.Tags)
+	if err != nil {
+		return nil, err
+	}
+	var heartbeatToken string
+	if m.Heartbeat != nil {
+		heartbeatToken = m.Heartbeat.Token
+	}
+
+	return []any{
+		m.ID,
+		string(m.Type),
+		m.URL,
+		int64(m.Interval.Seconds()),
+		m.Method,
+		string(headers),
+		m.Body,
+		string(auth),
+		string(tlsConfig),
+		m.Proxy,
+		m.ExpectedStatus,
+		string(assertions),
+		m.CertExpiryDays,
+		string(certificate),
+		string(dns),
+		string(steps),
+		string(heartbeat),
+		heartbeatToken,
+		string(watch),
+		string(cron),
+		string(tags),
+		m.Timeout.Milliseconds(),
+		m.SlowThreshold.Milliseconds(),
+		m.Retries,
+		m.RetryDelay.Milliseconds(),
+		boolToInt(m.FollowRedirects),
+		m.MaxRedirects,
+		m.ExpectedURL,
+		boolToInt(m.IsActive),
+		unixOrNil(m.LastChecked),
+		string(m.LastStatus),
+		m.CreatedAt.Unix(),
+		m.UpdatedAt.Unix(),
+	}, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMonitor(row rowScanner) (*monitor.URLMonitor, error) {
+	var m monitor.URLMonitor
+	var intervalSeconds, timeoutMs, slowThresholdMs, retryDelayMs int64
+	var headers, auth, tlsConfig, assertions, certificate, dns, steps, heartbeat, heartbeatToken, watch, cron, tags string
+	var followRedirects, isActive int
+	var lastChecked *int64
+	var monitorType, lastStatus string
+	var createdAt, updatedAt int64
+
+	err := row.Scan(&m.ID, &monitorType, &m.URL, &intervalSeconds, &m.Method, &headers, &m.Body, &auth, &tlsConfig, &m.Proxy, &m.ExpectedStatus, &assertions,
+		&m.CertExpiryDays, &certificate, &dns, &steps, &heartbeat, &heartbeatToken, &watch, &cron, &tags, &timeoutMs, &slowThresholdMs, &m.Retries, &retryDelayMs,
+		&followRedirects, &m.MaxRedirects, &m.ExpectedURL, &isActive, &lastChecked, &lastStatus, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(headers), &m.Headers); err != nil {
+		return nil, err
+	}
+	if m.Headers == nil {
+		m.Headers = map[string]string{}
+	}
+	if err := json.Unmarshal([]byte(auth), &m.Auth); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tlsConfig), &m.TLS); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(assertions), &m.Assertions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(certificate), &m.Certificate); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(dns), &m.DNS); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(steps), &m.Steps); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(heartbeat), &m.Heartbeat); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(watch), &m.Watch); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(cron), &m.Cron); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &m.Tags); err != nil {
+		return nil, err
+	}
+
+	m.Type = monitor.Type(monitorType)
+	m.Interval = time.Duration(intervalSeconds) * time.Second
+	m.Timeout = time.Duration(timeoutMs) * time.Millisecond
+	m.SlowThreshold = time.Duration(slowThresholdMs) * time.Millisecond
+	m.RetryDelay = time.Duration(retryDelayMs) * time.Millisecond
+	m.FollowRedirects = intToBool(followRedirects)
+	m.IsActive = intToBool(isActive)
+	m.LastChecked = timeOrNil(lastChecked)
+	m.LastStatus = monitor.Status(lastStatus)
+	m.CreatedAt = time.Unix(createdAt, 0)
+	m.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return &m, nil
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func unixOrNil(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	ts := t.Unix()
+	return &ts
+}
+
+func timeOrNil(ts *int64) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := time.Unix(*ts, 0)
+	return &t
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -126,3 +128,471 @@ func TestSQLiteRepository_Delete(t *testing.T) {
 		t.Error("expected monitor to be deleted")
 	}
 }
+
+func TestSQLiteRepository_FindResults(t *testing.T) {
+	dbPath := "test_results.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	now := time.Now()
+	old := monitor.NewCheckResult("m1", now.Add(-2*time.Hour))
+	old.Error = "connection refused"
+	old.ErrorKind = monitor.ErrorKindProxy
+	old.Attempts = 3
+	old.AttemptErrors = []string{"timeout", "timeout"}
+	recent := monitor.NewCheckResult("m1", now)
+	recent.Status = monitor.StatusUp
+	recent.StatusCode = 200
+	recent.ResponseTime = 150 * time.Millisecond
+	recent.Timings = &monitor.Timings{DNSLookup: 5 * time.Millisecond, TimeToFirstByte: 120 * time.Millisecond}
+	recent.RedirectChain = []string{"https://example.com/login"}
+	recent.Log = "backup done"
+	recent.ContentChanged = true
+	recent.InMaintenance = true
+	recent.Steps = []monitor.StepResult{{Name: "login", StatusCode: 200, ResponseTime: 80 * time.Millisecond}}
+	repo.SaveResult(old)
+	repo.SaveResult(recent)
+
+	all, err := repo.FindResults("m1", time.Time{}, time.Time{})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(all))
+	}
+	if all[0].Error != "connection refused" || all[0].ErrorKind != monitor.ErrorKindProxy {
+		t.Errorf("expected error to be stored, got %q (%s)", all[0].Error, all[0].ErrorKind)
+	}
+	if all[0].Attempts != 3 || len(all[0].AttemptErrors) != 2 {
+		t.Errorf("expected attempts to round-trip, got %d %v", all[0].Attempts, all[0].AttemptErrors)
+	}
+
+	filtered, _ := repo.FindResults("m1", now.Add(-time.Hour), now.Add(time.Hour))
+	if len(filtered) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(filtered))
+	}
+	if filtered[0].Status != monitor.StatusUp || filtered[0].StatusCode != 200 || filtered[0].ResponseTime != 150*time.Millisecond {
+		t.Errorf("unexpected result %+v", filtered[0])
+	}
+	if len(filtered[0].RedirectChain) != 1 {
+		t.Errorf("expected redirect chain to round-trip, got %v", filtered[0].RedirectChain)
+	}
+	if filtered[0].Log != "backup done" || !filtered[0].ContentChanged || !filtered[0].InMaintenance {
+		t.Errorf("expected log and content flag to round-trip, got %+v", filtered[0])
+	}
+	if len(filtered[0].Steps) != 1 || filtered[0].Steps[0].ResponseTime != 80*time.Millisecond {
+		t.Errorf("expected steps to round-trip, got %+v", filtered[0].Steps)
+	}
+	if filtered[0].Timings == nil || filtered[0].Timings.TimeToFirstByte != 120*time.Millisecond {
+		t.Errorf("expected timings to round-trip, got %+v", filtered[0].Timings)
+	}
+	if all[0].Timings != nil {
+		t.Errorf("expected no timings for old result, got %+v", all[0].Timings)
+	}
+}
+
+func TestSQLiteRepository_RequestConfig(t *testing.T) {
+	dbPath := "test_request.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	m := monitor.NewURLMonitor("https://example.com", 5*time.Minute)
+	m.SetRequest("POST", map[string]string{"X-Api-Key": "secret"}, `{"ping":true}`)
+	m.SetAuth(&monitor.AuthConfig{Type: monitor.AuthOAuth2, TokenURL: "https://auth.example.com/token", ClientID: "checker", ClientSecret: "s3cret", Scopes: []string{"read"}})
+	repo.Save(m)
+
+	found, err := repo.FindByID(m.ID)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found.Method != "POST" {
+		t.Errorf("expected method POST, got %s", found.Method)
+	}
+	if found.Headers["X-Api-Key"] != "secret" {
+		t.Errorf("expected headers to round-trip, got %v", found.Headers)
+	}
+	if found.Body != `{"ping":true}` {
+		t.Errorf("expected body to round-trip, got %s", found.Body)
+	}
+	if found.Auth == nil || found.Auth.ClientSecret != "s3cret" || len(found.Auth.Scopes) != 1 {
+		t.Errorf("expected auth to round-trip, got %+v", found.Auth)
+	}
+}
+
+func TestSQLiteRepository_Type(t *testing.T) {
+	dbPath := "test_type.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	m := monitor.NewURLMonitor("tcp://db.internal:5432", 5*time.Minute)
+	repo.Save(m)
+
+	found, _ := repo.FindByID(m.ID)
+
+	if found.Type != monitor.TypeTCP {
+		t.Errorf("expected type tcp, got %s", found.Type)
+	}
+
+	d := monitor.NewURLMonitor("dns://example.com", 5*time.Minute)
+	d.SetDNSCheck(&monitor.DNSCheck{Server: "1.1.1.1", Record: monitor.DNSRecordMX, Expected: []string{"mail.example.com"}})
+	repo.Save(d)
+
+	found, _ = repo.FindByID(d.ID)
+
+	if found.Type != monitor.TypeDNS || found.DNS == nil || found.DNS.Record != monitor.DNSRecordMX {
+		t.Errorf("expected dns config to round-trip, got %s %+v", found.Type, found.DNS)
+	}
+
+	tx := monitor.NewURLMonitor("https://shop.example.com", 5*time.Minute)
+	tx.SetType(monitor.TypeTransaction)
+	tx.SetSteps([]monitor.TransactionStep{{
+		Name:    "login",
+		URL:     "/login",
+		Extract: []monitor.Extraction{{Variable: "token", Source: monitor.ExtractJSONPath, Expression: "$.token"}},
+	}})
+	repo.Save(tx)
+
+	found, _ = repo.FindByID(tx.ID)
+
+	if found.Type != monitor.TypeTransaction || len(found.Steps) != 1 || found.Steps[0].Extract[0].Variable != "token" {
+		t.Errorf("expected transaction steps to round-trip, got %s %+v", found.Type, found.Steps)
+	}
+}
+
+func TestSQLiteRepository_FindByHeartbeatToken(t *testing.T) {
+	dbPath := "test_heartbeat.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	repo.Save(monitor.NewURLMonitor("https://example.com", 5*time.Minute))
+	m := monitor.NewURLMonitor("", time.Hour)
+	m.SetType(monitor.TypeHeartbeat)
+	m.SetHeartbeat(10 * time.Minute)
+	m.RecordPing(monitor.PingStart, "", time.Unix(1700000000, 0))
+	repo.Save(m)
+
+	found, err := repo.FindByHeartbeatToken(m.Heartbeat.Token)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found.ID != m.ID || found.Heartbeat.Grace != 10*time.Minute || found.Heartbeat.LastStart == nil {
+		t.Errorf("expected heartbeat to round-trip, got %+v", found.Heartbeat)
+	}
+	if _, err := repo.FindByHeartbeatToken(""); err == nil {
+		t.Error("expected monitors without a token not to match")
+	}
+}
+
+func TestSQLiteRepository_Snapshots(t *testing.T) {
+	dbPath := "test_snapshots.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	m := monitor.NewURLMonitor("https://example.com/status", 5*time.Minute)
+	m.SetWatch(&monitor.Watch{Select: "$.components", Ignore: []string{`\d+ms`}, Normalize: true})
+	m.SetCron("*/5 9-17 * * MON-FRI", "Europe/Kyiv")
+	repo.Save(m)
+
+	first := &monitor.Snapshot{MonitorID: m.ID, CapturedAt: time.Unix(1700000000, 0), Hash: "h1", Content: "v1"}
+	second := &monitor.Snapshot{MonitorID: m.ID, CapturedAt: time.Unix(1700000600, 0), Hash: "h2", Content: "v2"}
+	repo.SaveSnapshot(first)
+	repo.SaveSnapshot(second)
+
+	found, _ := repo.FindByID(m.ID)
+	if found.Watch == nil || found.Watch.Select != "$.components" || !found.Watch.Normalize || len(found.Watch.Ignore) != 1 {
+		t.Errorf("expected watch to round-trip, got %+v", found.Watch)
+	}
+	if found.Cron == nil || *found.Cron != *m.Cron {
+		t.Errorf("expected cron to round-trip, got %+v", found.Cron)
+	}
+
+	snapshots, err := repo.FindSnapshots(m.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].ID != first.ID || snapshots[1].Content != "v2" {
+		t.Errorf("expected snapshots oldest first, got %+v", snapshots)
+	}
+
+	got, err := repo.FindSnapshot(m.ID, second.ID)
+	if err != nil || got.Hash != "h2" || !got.CapturedAt.Equal(second.CapturedAt) {
+		t.Errorf("expected snapshot to round-trip, got %+v (%v)", got, err)
+	}
+	if _, err := repo.FindSnapshot("other", second.ID); err == nil {
+		t.Error("expected snapshot of another monitor not to be found")
+	}
+
+	repo.Delete(m.ID)
+	if snapshots, _ := repo.FindSnapshots(m.ID); len(snapshots) != 0 {
+		t.Errorf("expected snapshots to be deleted with the monitor, got %d", len(snapshots))
+	}
+}
+
+func TestSQLiteRepository_MaintenanceWindows(t *testing.T) {
+	dbPath := "test_maintenance.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	m := monitor.NewURLMonitor("https://example.com", 5*time.Minute)
+	m.SetTags([]string{"payments", "eu"})
+	repo.Save(m)
+
+	oneOff := monitor.NewMaintenanceWindow("deploy")
+	oneOff.SetMode(monitor.MaintenanceSkip)
+	oneOff.SetScope([]string{m.ID}, nil)
+	oneOff.SetTimes(time.Unix(1700000000, 0), time.Unix(1700003600, 0), nil, 0)
+	recurring := monitor.NewMaintenanceWindow("backup")
+	recurring.SetScope(nil, []string{"payments"})
+	recurring.SetTimes(time.Time{}, time.Time{}, &monitor.CronSchedule{Expression: "0 2 * * *", TimeZone: "UTC"}, 30*time.Minute)
+	repo.SaveWindow(oneOff)
+	repo.SaveWindow(recurring)
+
+	found, _ := repo.FindByID(m.ID)
+	if len(found.Tags) != 2 || found.Tags[1] != "eu" {
+		t.Errorf("expected tags to round-trip, got %v", found.Tags)
+	}
+
+	got, err := repo.FindWindow(oneOff.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Mode != monitor.MaintenanceSkip || !got.End.Equal(oneOff.End) || got.MonitorIDs[0] != m.ID || got.Recurrence != nil {
+		t.Errorf("expected one-off window to round-trip, got %+v", got)
+	}
+
+	got, _ = repo.FindWindow(recurring.ID)
+	if got.Recurrence == nil || got.Duration != 30*time.Minute || !got.Start.IsZero() || got.Tags[0] != "payments" {
+		t.Errorf("expected recurring window to round-trip, got %+v", got)
+	}
+
+	recurring.Name = "nightly backup"
+	repo.UpdateWindow(recurring)
+	repo.DeleteWindow(oneOff.ID)
+
+	windows, _ := repo.FindWindows()
+	if len(windows) != 1 || windows[0].Name != "nightly backup" {
+		t.Errorf("expected the updated window only, got %+v", windows)
+	}
+}
+
+func TestSQLiteRepository_CheckConfig(t *testing.T) {
+	dbPath := "test_expected_status.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	m := monitor.NewURLMonitor("https://example.com", 5*time.Minute)
+	m.SetExpectedStatus("200-299,301")
+	m.SetAssertions([]monitor.BodyAssertion{{Type: monitor.AssertJSONPath, Path: "$.status", Value: "ok"}})
+	m.SetCertExpiryDays(30)
+	m.SetTimeouts(2500*time.Millisecond, time.Second)
+	m.SetRetryPolicy(2, 3*time.Second)
+	m.SetRedirectPolicy(false, 3, "https://example.com/home")
+	m.SetTLSConfig(&monitor.TLSConfig{CAFile: "/etc/ssl/private-ca.pem", ServerName: "api.internal"})
+	m.SetProxy("socks5://egress:1080")
+	m.Certificate = &monitor.CertificateInfo{Issuer: "CN=Test CA", DNSNames: []string{"example.com"}, NotAfter: time.Unix(1900000000, 0)}
+	m.LastStatus = monitor.StatusDown
+	repo.Save(m)
+
+	found, _ := repo.FindByID(m.ID)
+
+	if found.ExpectedStatus != "200-299,301" {
+		t.Errorf("expected rule 200-299,301, got %s", found.ExpectedStatus)
+	}
+	if found.LastStatus != monitor.StatusDown {
+		t.Errorf("expected last status down, got %s", found.LastStatus)
+	}
+	if len(found.Assertions) != 1 || found.Assertions[0].Path != "$.status" {
+		t.Errorf("expected assertions to round-trip, got %+v", found.Assertions)
+	}
+	if found.Timeout != 2500*time.Millisecond || found.SlowThreshold != time.Second {
+		t.Errorf("expected timeouts to round-trip, got %v / %v", found.Timeout, found.SlowThreshold)
+	}
+	if found.Retries != 2 || found.RetryDelay != 3*time.Second {
+		t.Errorf("expected retry policy to round-trip, got %d / %v", found.Retries, found.RetryDelay)
+	}
+	if found.FollowRedirects || found.MaxRedirects != 3 || found.ExpectedURL != "https://example.com/home" {
+		t.Errorf("expected redirect policy to round-trip, got %v / %d / %s", found.FollowRedirects, found.MaxRedirects, found.ExpectedURL)
+	}
+	if found.TLS == nil || found.TLS.CAFile != "/etc/ssl/private-ca.pem" || found.TLS.ServerName != "api.internal" {
+		t.Errorf("expected tls profile to round-trip, got %+v", found.TLS)
+	}
+	if found.Proxy != "socks5://egress:1080" {
+		t.Errorf("expected proxy to round-trip, got %q", found.Proxy)
+	}
+	if found.CertExpiryDays != 30 {
+		t.Errorf("expected cert expiry threshold 30, got %d", found.CertExpiryDays)
+	}
+	if found.Certificate == nil || found.Certificate.Issuer != "CN=Test CA" || !found.Certificate.NotAfter.Equal(time.Unix(1900000000, 0)) {
+		t.Errorf("expected certificate to round-trip, got %+v", found.Certificate)
+	}
+}
+
+func TestSQLiteRepository_MigratesLegacySchema(t *testing.T) {
+	dbPath := "test_migrate.db"
+	defer os.Remove(dbPath)
+
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	_, err = legacy.Exec(`
+	CREATE TABLE monitors (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		interval_seconds INTEGER NOT NULL,
+		is_active INTEGER NOT NULL,
+		last_checked INTEGER,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+	INSERT INTO monitors VALUES ('legacy', 'https://example.com', 300, 1, NULL, 0, 0)`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("failed to create legacy schema: %v", err)
+	}
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	found, err := repo.FindByID("legacy")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found.Method != "GET" {
+		t.Errorf("expected default method GET, got %s", found.Method)
+	}
+}
+
+func TestSQLiteRepository_Leases(t *testing.T) {
+	dbPath := "test_leases.db"
+	defer os.Remove(dbPath)
+
+	// Два экземпляра работают с одним файлом
+	first, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer first.Close()
+	second, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer second.Close()
+
+	now := time.Now()
+	if ok, err := first.AcquireLease("m1", "a", now, time.Minute); err != nil || !ok {
+		t.Fatalf("expected a to acquire a free lease, got %v, %v", ok, err)
+	}
+	if ok, _ := second.AcquireLease("m1", "b", now, time.Minute); ok {
+		t.Error("expected b not to acquire a lease held by a")
+	}
+	if ok, _ := first.AcquireLease("m1", "a", now.Add(30*time.Second), time.Minute); !ok {
+		t.Error("expected a to extend its own lease")
+	}
+
+	// Продление переносит истечение, поэтому b не может забрать аренду
+	first.RenewLeases("a", now.Add(time.Minute), time.Minute)
+	if ok, _ := second.AcquireLease("m1", "b", now.Add(90*time.Second), time.Minute); ok {
+		t.Error("expected a renewed lease to stay with a")
+	}
+	if ok, _ := second.AcquireLease("m1", "b", now.Add(2*time.Minute), time.Minute); !ok {
+		t.Error("expected b to take over an expired lease")
+	}
+
+	second.ReleaseLeases("b")
+	if ok, _ := first.AcquireLease("m1", "a", now.Add(2*time.Minute), time.Minute); !ok {
+		t.Error("expected a to acquire a released lease")
+	}
+}
+
+func TestSQLiteRepository_UpdateCheckState(t *testing.T) {
+	dbPath := "test_check_state.db"
+	defer os.Remove(dbPath)
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	m := monitor.NewURLMonitor("https://example.com", 5*time.Minute)
+	m.SetWatch(&monitor.Watch{Select: "$.price"})
+	repo.Save(m)
+
+	// Проверка работает со своей копией, пока монитор редактируют
+	checked, _ := repo.FindByID(m.ID)
+	m.Update("https://example.org", 10*time.Minute)
+	repo.Update(m)
+
+	now := time.Unix(1700000000, 0)
+	checked.LastChecked = &now
+	checked.LastStatus = monitor.StatusDown
+	checked.Watch.LastHash = "abc"
+	if err := repo.UpdateCheckState(checked); err != nil {
+		t.Fatalf("failed to update check state: %v", err)
+	}
+
+	found, _ := repo.FindByID(m.ID)
+	if found.URL != "https://example.org" || found.Interval != 10*time.Minute {
+		t.Errorf("expected the edit to be kept, got %s every %v", found.URL, found.Interval)
+	}
+	if found.LastStatus != monitor.StatusDown || !found.LastChecked.Equal(now) {
+		t.Errorf("expected check state to be saved, got %s at %v", found.LastStatus, found.LastChecked)
+	}
+	if found.Watch == nil || found.Watch.LastHash != "abc" || found.Watch.Select != "$.price" {
+		t.Errorf("expected watch hash to be saved, got %+v", found.Watch)
+	}
+
+	changed, _ := repo.FindUpdatedSince(m.UpdatedAt)
+	if len(changed) != 1 || changed[0].ID != m.ID {
+		t.Errorf("expected the edited monitor to be found, got %d", len(changed))
+	}
+	if changed, _ := repo.FindUpdatedSince(m.UpdatedAt.Add(time.Hour)); len(changed) != 0 {
+		t.Errorf("expected no monitors updated later, got %d", len(changed))
+	}
+
+	if _, err := repo.FindByID("missing"); !errors.Is(err, monitor.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"urlChecker/internal/domain/monitor"
+)
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	cases := map[string]time.Duration{
+		`"15s"`:   15 * time.Second,
+		`"1m30s"`: 90 * time.Second,
+		`10`:      10 * time.Second,
+		`0.5`:     500 * time.Millisecond,
+		`null`:    0,
+	}
+
+	for input, want := range cases {
+		var d Duration
+		if err := json.Unmarshal([]byte(input), &d); err != nil {
+			t.Errorf("unmarshal %s: unexpected error %v", input, err)
+			continue
+		}
+		if time.Duration(d) != want {
+			t.Errorf("unmarshal %s: expected %v, got %v", input, want, time.Duration(d))
+		}
+	}
+}
+
+func TestDuration_UnmarshalJSON_Invalid(t *testing.T) {
+	for _, input := range []string{`"soon"`, `true`, `[1]`} {
+		var d Duration
+		if err := json.Unmarshal([]byte(input), &d); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
+
+func TestDuration_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(Duration(90 * time.Second))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(data) != `"1m30s"` {
+		t.Errorf("expected \"1m30s\", got %s", data)
+	}
+}
+
+func TestCreateMonitorRequest_Interval(t *testing.T) {
+	for input, want := range map[string]time.Duration{`"15s"`: 15 * time.Second, `30`: 30 * time.Second} {
+		var req CreateMonitorRequest
+		if err := json.Unmarshal([]byte(`{"url":"https://example.com","interval":`+input+`}`), &req); err != nil {
+			t.Fatalf("unmarshal %s: unexpected error %v", input, err)
+		}
+		if got := req.params().Interval; got != want {
+			t.Errorf("interval %s: expected %v, got %v", input, want, got)
+		}
+	}
+}
+
+func TestMonitorResponse_WritesDurationStrings(t *testing.T) {
+	m := monitor.NewURLMonitor("https://example.com", 15*time.Second)
+
+	data, err := json.Marshal(newMonitorResponse(m, time.Now()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	if fields["Interval"] != "15s" || fields["Timeout"] != monitor.DefaultTimeout.String() {
+		t.Errorf("expected duration strings, got interval %v and timeout %v", fields["Interval"], fields["Timeout"])
+	}
+}
+
+func TestMaintenanceResponse_WritesDurationString(t *testing.T) {
+	w := monitor.NewMaintenanceWindow("nightly")
+	w.Duration = 90 * time.Minute
+
+	data, err := json.Marshal(newMaintenanceResponse(w, time.Now()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	if fields["Duration"] != "1h30m0s" {
+		t.Errorf("expected duration string, got %v", fields["Duration"])
+	}
+}
+
+func TestMonitorResponse_WritesHeartbeatGrace(t *testing.T) {
+	m := monitor.NewURLMonitor("", time.Hour)
+	m.SetType(monitor.TypeHeartbeat)
+	if err := m.SetHeartbeat(5 * time.Minute); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := json.Marshal(newMonitorResponse(m, time.Now()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var resp struct{ Heartbeat map[string]any }
+	json.Unmarshal(data, &resp)
+	if resp.Heartbeat["Grace"] != "5m0s" || resp.Heartbeat["Token"] != m.Heartbeat.Token {
+		t.Errorf("expected the heartbeat with a grace string, got %v", resp.Heartbeat)
+	}
+}
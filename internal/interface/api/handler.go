@@ -2,10 +2,22 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 	"urlChecker/internal/application/service"
+	"urlChecker/internal/domain/monitor"
 )
 
+const maxPingLogSize = 10 << 10
+
+// nextRunsShown is how many upcoming cron runs a monitor response lists.
+const nextRunsShown = 5
+
 type Handler struct {
 	service *service.MonitorService
 }
@@ -15,13 +27,266 @@ func NewHandler(service *service.MonitorService) *Handler {
 }
 
 type CreateMonitorRequest struct {
-	URL      string `json:"url"`
-	Interval int    `json:"interval"`
+	Type            monitor.Type            `json:"type"`
+	URL             string                  `json:"url"`
+	Interval        Duration                `json:"interval"`
+	Cron            string                  `json:"cron"`
+	TimeZone        string                  `json:"time_zone"`
+	Tags            []string                `json:"tags"`
+	Method          string                  `json:"method"`
+	Headers         map[string]string       `json:"headers"`
+	Body            string                  `json:"body"`
+	Auth            *AuthRequest            `json:"auth"`
+	TLS             *TLSRequest             `json:"tls"`
+	Proxy           string                  `json:"proxy"`
+	ExpectedStatus  string                  `json:"expected_status"`
+	Assertions      []monitor.BodyAssertion `json:"assertions"`
+	CertExpiryDays  *int                    `json:"cert_expiry_days"`
+	DNS             *monitor.DNSCheck       `json:"dns"`
+	Steps           []StepRequest           `json:"steps"`
+	Grace           Duration                `json:"grace"`
+	Watch           *WatchRequest           `json:"watch"`
+	Timeout         Duration                `json:"timeout"`
+	SlowThreshold   Duration                `json:"slow_threshold"`
+	Retries         int                     `json:"retries"`
+	RetryDelay      Duration                `json:"retry_delay"`
+	FollowRedirects *bool                   `json:"follow_redirects"`
+	MaxRedirects    int                     `json:"max_redirects"`
+	ExpectedURL     string                  `json:"expected_url"`
+}
+
+type UpdateMonitorRequest = CreateMonitorRequest
+
+func (r CreateMonitorRequest) params() service.MonitorParams {
+	return service.MonitorParams{
+		Type:            r.Type,
+		URL:             r.URL,
+		Interval:        time.Duration(r.Interval),
+		Cron:            r.Cron,
+		TimeZone:        r.TimeZone,
+		Tags:            r.Tags,
+		Method:          r.Method,
+		Headers:         r.Headers,
+		Body:            r.Body,
+		Auth:            r.Auth.config(),
+		TLS:             r.TLS.config(),
+		Proxy:           r.Proxy,
+		ExpectedStatus:  r.ExpectedStatus,
+		Assertions:      r.Assertions,
+		CertExpiryDays:  r.CertExpiryDays,
+		DNS:             r.DNS,
+		Steps:           stepConfigs(r.Steps),
+		Grace:           time.Duration(r.Grace),
+		Watch:           r.Watch.config(),
+		Timeout:         time.Duration(r.Timeout),
+		SlowThreshold:   time.Duration(r.SlowThreshold),
+		Retries:         r.Retries,
+		RetryDelay:      time.Duration(r.RetryDelay),
+		FollowRedirects: r.FollowRedirects,
+		MaxRedirects:    r.MaxRedirects,
+		ExpectedURL:     r.ExpectedURL,
+	}
+}
+
+type StepRequest struct {
+	Name           string                  `json:"name"`
+	Method         string                  `json:"method"`
+	URL            string                  `json:"url"`
+	Headers        map[string]string       `json:"headers"`
+	Body           string                  `json:"body"`
+	ExpectedStatus string                  `json:"expected_status"`
+	Assertions     []monitor.BodyAssertion `json:"assertions"`
+	Extract        []monitor.Extraction    `json:"extract"`
+}
+
+func stepConfigs(steps []StepRequest) []monitor.TransactionStep {
+	if steps == nil {
+		return nil
+	}
+	configs := make([]monitor.TransactionStep, len(steps))
+	for i, step := range steps {
+		configs[i] = monitor.TransactionStep{
+			Name:           step.Name,
+			Method:         step.Method,
+			URL:            step.URL,
+			Headers:        step.Headers,
+			Body:           step.Body,
+			ExpectedStatus: step.ExpectedStatus,
+			Assertions:     step.Assertions,
+			Extract:        step.Extract,
+		}
+	}
+	return configs
+}
+
+type WatchRequest struct {
+	Select    string   `json:"select"`
+	Ignore    []string `json:"ignore"`
+	Normalize bool     `json:"normalize"`
+}
+
+func (r *WatchRequest) config() *monitor.Watch {
+	if r == nil {
+		return nil
+	}
+	return &monitor.Watch{
+		Select:    r.Select,
+		Ignore:    r.Ignore,
+		Normalize: r.Normalize,
+	}
+}
+
+type AuthRequest struct {
+	Type         monitor.AuthType `json:"type"`
+	Username     string           `json:"username"`
+	Password     string           `json:"password"`
+	Token        string           `json:"token"`
+	TokenURL     string           `json:"token_url"`
+	ClientID     string           `json:"client_id"`
+	ClientSecret string           `json:"client_secret"`
+	Scopes       []string         `json:"scopes"`
+}
+
+func (r *AuthRequest) config() *monitor.AuthConfig {
+	if r == nil {
+		return nil
+	}
+	return &monitor.AuthConfig{
+		Type:         r.Type,
+		Username:     r.Username,
+		Password:     r.Password,
+		Token:        r.Token,
+		TokenURL:     r.TokenURL,
+		ClientID:     r.ClientID,
+		ClientSecret: r.ClientSecret,
+		Scopes:       r.Scopes,
+	}
+}
+
+type TLSRequest struct {
+	CertFile           string `json:"cert_file"`
+	KeyFile            string `json:"key_file"`
+	CAFile             string `json:"ca_file"`
+	ServerName         string `json:"server_name"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+func (r *TLSRequest) config() *monitor.TLSConfig {
+	if r == nil {
+		return nil
+	}
+	return &monitor.TLSConfig{
+		CertFile:           r.CertFile,
+		KeyFile:            r.KeyFile,
+		CAFile:             r.CAFile,
+		ServerName:         r.ServerName,
+		InsecureSkipVerify: r.InsecureSkipVerify,
+	}
+}
+
+// AuthSummary is the auth configuration without secrets.
+type AuthSummary struct {
+	Type     monitor.AuthType
+	Username string   `json:",omitempty"`
+	TokenURL string   `json:",omitempty"`
+	ClientID string   `json:",omitempty"`
+	Scopes   []string `json:",omitempty"`
+}
+
+// HeartbeatResponse shadows Heartbeat.Grace so that it is written as a
+// duration string.
+type HeartbeatResponse struct {
+	*monitor.Heartbeat
+	Grace Duration
+}
+
+// MonitorResponse shadows URLMonitor.Auth, Proxy, Headers and Steps so that
+// credentials are never returned by the API, and the durations so that they
+// are written as duration strings like the requests accept. NextRuns lists
+// the upcoming runs of a cron schedule.
+type MonitorResponse struct {
+	*monitor.URLMonitor
+	Auth                *AuthSummary
+	Proxy               string
+	Headers             map[string]string
+	Steps               []monitor.TransactionStep
+	Heartbeat           *HeartbeatResponse
+	Interval            Duration
+	Timeout             Duration
+	SlowThreshold       Duration
+	RetryDelay          Duration
+	CertificateDaysLeft *int        `json:",omitempty"`
+	NextRuns            []time.Time `json:",omitempty"`
+}
+
+func newMonitorResponse(m *monitor.URLMonitor, now time.Time) MonitorResponse {
+	resp := MonitorResponse{
+		URLMonitor:    m,
+		Proxy:         m.Proxy,
+		Headers:       redactHeaders(m.Headers),
+		Interval:      Duration(m.Interval),
+		Timeout:       Duration(m.Timeout),
+		SlowThreshold: Duration(m.SlowThreshold),
+		RetryDelay:    Duration(m.RetryDelay),
+	}
+	if proxyURL, err := url.Parse(m.Proxy); err == nil {
+		resp.Proxy = proxyURL.Redacted()
+	}
+	for _, step := range m.Steps {
+		step.Headers = redactHeaders(step.Headers)
+		if step.Body != "" {
+			step.Body = redacted
+		}
+		resp.Steps = append(resp.Steps, step)
+	}
+	if m.Heartbeat != nil {
+		resp.Heartbeat = &HeartbeatResponse{Heartbeat: m.Heartbeat, Grace: Duration(m.Heartbeat.Grace)}
+	}
+	if m.Auth != nil {
+		resp.Auth = &AuthSummary{
+			Type:     m.Auth.Type,
+			Username: m.Auth.Username,
+			TokenURL: m.Auth.TokenURL,
+			ClientID: m.Auth.ClientID,
+			Scopes:   m.Auth.Scopes,
+		}
+	}
+	if m.Certificate != nil {
+		days := m.Certificate.DaysUntilExpiry(now)
+		resp.CertificateDaysLeft = &days
+	}
+	if m.Cron != nil {
+		resp.NextRuns = m.Cron.NextRuns(now, nextRunsShown)
+	}
+	return resp
 }
 
-type UpdateMonitorRequest struct {
-	URL      string `json:"url"`
-	Interval int    `json:"interval"`
+// redacted replaces secrets in responses, as url.URL.Redacted does.
+const redacted = "xxxxx"
+
+// redactHeaders copies headers with the values of those that usually carry
+// credentials replaced.
+func redactHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if sensitiveHeader(name) {
+			value = redacted
+		}
+		copied[name] = value
+	}
+	return copied
+}
+
+func sensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "authorization", "proxy-authorization", "cookie":
+		return true
+	}
+	return strings.HasSuffix(name, "-key") || strings.HasSuffix(name, "-token")
 }
 
 func (h *Handler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
@@ -31,14 +296,14 @@ func (h *Handler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	m, err := h.service.CreateMonitor(req.URL, req.Interval)
+	m, err := h.service.CreateMonitor(req.params())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(m)
+	json.NewEncoder(w).Encode(newMonitorResponse(m, time.Now()))
 }
 
 func (h *Handler) GetAllMonitors(w http.ResponseWriter, r *http.Request) {
@@ -48,8 +313,14 @@ func (h *Handler) GetAllMonitors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	now := time.Now()
+	resp := make([]MonitorResponse, len(monitors))
+	for i, m := range monitors {
+		resp[i] = newMonitorResponse(m, now)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(monitors)
+	json.NewEncoder(w).Encode(resp)
 }
 
 func (h *Handler) GetMonitor(w http.ResponseWriter, r *http.Request) {
@@ -61,7 +332,101 @@ func (h *Handler) GetMonitor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(m)
+	json.NewEncoder(w).Encode(newMonitorResponse(m, time.Now()))
+}
+
+func (h *Handler) GetResults(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.service.GetResults(id, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// SnapshotSummary lists a snapshot without its content.
+type SnapshotSummary struct {
+	ID         int64
+	CapturedAt time.Time
+	Hash       string
+	Size       int
+}
+
+func (h *Handler) GetSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := h.service.GetSnapshots(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	resp := make([]SnapshotSummary, len(snapshots))
+	for i, snapshot := range snapshots {
+		resp[i] = SnapshotSummary{
+			ID:         snapshot.ID,
+			CapturedAt: snapshot.CapturedAt,
+			Hash:       snapshot.Hash,
+			Size:       len(snapshot.Content),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshotID, err := strconv.ParseInt(r.PathValue("snapshot"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid snapshot id", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := h.service.GetSnapshot(r.PathValue("id"), snapshotID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// DiffSnapshots writes a unified diff between the snapshots named by the
+// from and to query parameters, by default the latest two.
+func (h *Handler) DiffSnapshots(w http.ResponseWriter, r *http.Request) {
+	from, err := parseIDParam(r, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseIDParam(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.service.DiffSnapshots(r.PathValue("id"), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+	io.WriteString(w, diff)
 }
 
 func (h *Handler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
@@ -72,9 +437,9 @@ func (h *Handler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.UpdateMonitor(id, req.URL, req.Interval)
+	err := h.service.UpdateMonitor(id, req.params())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -113,3 +478,52 @@ func (h *Handler) ResumeMonitor(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// Heartbeat receives pings from heartbeat monitors. The optional kind
+// suffix is start, success or fail; the request body is kept as the log.
+func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	kind, err := monitor.ParsePingKind(r.PathValue("kind"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	log, err := io.ReadAll(io.LimitReader(r.Body, maxPingLogSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RecordHeartbeat(r.PathValue("token"), kind, string(log)); err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseIDParam(r *http.Request, name string) (int64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func writeError(w http.ResponseWriter, err error, status int) {
+	switch {
+	case errors.Is(err, monitor.ErrInvalidConfig):
+		status = http.StatusBadRequest
+	case errors.Is(err, monitor.ErrNotFound):
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+	"urlChecker/internal/application/service"
+	"urlChecker/internal/domain/monitor"
+)
+
+type MaintenanceRequest struct {
+	Name       string                  `json:"name"`
+	Mode       monitor.MaintenanceMode `json:"mode"`
+	MonitorIDs []string                `json:"monitor_ids"`
+	Tags       []string                `json:"tags"`
+	Start      time.Time               `json:"start"`
+	End        time.Time               `json:"end"`
+	Cron       string                  `json:"cron"`
+	TimeZone   string                  `json:"time_zone"`
+	Duration   Duration                `json:"duration"`
+}
+
+func (r MaintenanceRequest) params() service.MaintenanceParams {
+	return service.MaintenanceParams{
+		Name:       r.Name,
+		Mode:       r.Mode,
+		MonitorIDs: r.MonitorIDs,
+		Tags:       r.Tags,
+		Start:      r.Start,
+		End:        r.End,
+		Cron:       r.Cron,
+		TimeZone:   r.TimeZone,
+		Duration:   time.Duration(r.Duration),
+	}
+}
+
+// MaintenanceResponse tells whether the window is open right now and shadows
+// Duration so that it is written as a duration string like the requests
+// accept.
+type MaintenanceResponse struct {
+	*monitor.MaintenanceWindow
+	Duration Duration
+	Active   bool
+}
+
+func newMaintenanceResponse(w *monitor.MaintenanceWindow, now time.Time) MaintenanceResponse {
+	return MaintenanceResponse{MaintenanceWindow: w, Duration: Duration(w.Duration), Active: w.ActiveAt(now)}
+}
+
+func (h *Handler) CreateMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	window, err := h.service.CreateMaintenance(req.params())
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newMaintenanceResponse(window, time.Now()))
+}
+
+func (h *Handler) GetAllMaintenance(w http.ResponseWriter, r *http.Request) {
+	windows, err := h.service.GetAllMaintenance()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	resp := make([]MaintenanceResponse, len(windows))
+	for i, window := range windows {
+		resp[i] = newMaintenanceResponse(window, now)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	window, err := h.service.GetMaintenance(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newMaintenanceResponse(window, time.Now()))
+}
+
+func (h *Handler) UpdateMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.UpdateMaintenance(r.PathValue("id"), req.params()); err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) DeleteMaintenance(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteMaintenance(r.PathValue("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}